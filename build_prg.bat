@@ -7,9 +7,15 @@ go build -ldflags "-s -w" cmd\t024\t024.go
 go build -ldflags "-s -w" cmd\t024e\t024e.go
 go build -ldflags "-s -w" cmd\tcurr\tcurr.go
 go build -ldflags "-s -w" cmd\zstxl\zstxl.go
+go build -ldflags "-s -w" cmd\extract\extract.go
+go build -ldflags "-s -w" cmd\load\load.go
+go build -ldflags "-s -w" cmd\snapshot\snapshot.go
+go build -ldflags "-s -w" cmd\diff\diff.go
 
 echo "Build other utilities"
+go build -ldflags "-s -w" cmd\config\config.go
+go build -ldflags "-s -w" cmd\perftest\perftest.go
 go build -ldflags "-s -w" cmd\dl_consolpack_rtemplate\dl_consolpack_rtemplate.go
 go build -ldflags "-s -w" cmd\dl_gl_consol_pack_map\dl_gl_consol_pack_map.go
-go build -ldflags "-s -w" cmd\gen_code_ddf\gen_code_ddf.go
+go build -ldflags "-s -w" .\cmd\gen_code_ddf
 go build -ldflags "-s -w" cmd\upload_gl_consol_pack_map\upload_gl_consol_pack_map.go
\ No newline at end of file
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+	// cli
+	"github.com/urfave/cli"
+)
+
+func main() {
+	var sCfg, sKey string
+	var bPing bool
+
+	app := cli.NewApp()
+	app.Name = "config"
+	app.Usage = "Inspect config.ini"
+	app.Version = "0.0.1"
+
+	app.Flags = []cli.Flag{
+		extract.ConfigFlag(&sCfg),
+	}
+
+	app.Commands = []cli.Command{
+		{
+			Name:  "check",
+			Usage: "Validate [server] and every [server.<name>] profile, report missing or invalid keys",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:        "ping",
+					Usage:       "Also connect to every valid profile",
+					Destination: &bPing,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				// every profile is checked by name, [server] must not follow the environment
+				os.Unsetenv(utils.ProfileEnv)
+
+				checks, err := utils.CheckConfig(sCfg)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				failed := 0
+				for _, check := range checks {
+					name := check.Name
+					if name == "" {
+						name = "[server]"
+					}
+					if check.Host != "" {
+						name += " " + check.Host
+					}
+					if len(check.Problems) == 0 && bPing {
+						if err := ping(sCfg, check.Name); err != nil {
+							check.Problems = append(check.Problems, "ping: "+err.Error())
+						}
+					}
+					for _, warning := range check.Warnings {
+						fmt.Printf("WARN %s: %s\n", name, warning)
+					}
+					if len(check.Problems) != 0 {
+						failed++
+						fmt.Printf("FAIL %s: %s\n", name, strings.Join(check.Problems, "; "))
+						continue
+					}
+					fmt.Printf("OK   %s\n", name)
+				}
+				if failed != 0 {
+					return cli.NewExitError(fmt.Sprintf("%d of %d profiles failed", failed, len(checks)), 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "keygen",
+			Usage:     "Write a new key file for encrypt",
+			ArgsUsage: "<key_file>",
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					log.Fatal("You need to enter the key file")
+				}
+				key, err := utils.NewSecretKey()
+				if err != nil {
+					log.Fatal(err)
+				}
+				// O_EXCL, an existing key may still unlock a secrets file
+				f, err := os.OpenFile(c.Args().First(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+				if err != nil {
+					log.Fatal(err)
+				}
+				if _, err := fmt.Fprintln(f, key); err != nil {
+					f.Close()
+					log.Fatal(err)
+				}
+				if err := f.Close(); err != nil {
+					log.Fatal(err)
+				}
+				utils.WriteMsg("KEY FILE: " + c.Args().First())
+				return nil
+			},
+		},
+		{
+			Name:      "encrypt",
+			Usage:     "Encrypt an ini file of [<profile>] uid, pwd sections into a secrets_file",
+			ArgsUsage: "<secrets.ini> <secrets_file>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:        "key, k",
+					Usage:       "Key file written by keygen",
+					Destination: &sKey,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 || sKey == "" {
+					log.Fatal("You need to enter --key, the plain ini file and the secrets file")
+				}
+				key, err := utils.ReadKeyFile(sKey)
+				if err != nil {
+					log.Fatal(err)
+				}
+				plain, err := ioutil.ReadFile(c.Args().Get(0))
+				if err != nil {
+					log.Fatal(err)
+				}
+				data, err := utils.EncryptSecrets(key, plain)
+				if err != nil {
+					log.Fatal(err)
+				}
+				if err := ioutil.WriteFile(c.Args().Get(1), data, 0600); err != nil {
+					log.Fatal(err)
+				}
+				utils.WriteMsg("SECRETS FILE: " + c.Args().Get(1))
+				utils.WriteMsg("DELETE THE PLAIN FILE: " + c.Args().Get(0))
+				return nil
+			},
+		},
+	}
+
+	// init the program
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ping - Connect with profile of config p
+func ping(p, profile string) error {
+	cfg, err := utils.LoadProfile(p, profile)
+	if err != nil {
+		return err
+	}
+	db, err := cfg.Open()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
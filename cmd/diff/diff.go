@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/load"
+	// cli
+	"github.com/urfave/cli"
+)
+
+func main() {
+	var sCfg, sProfile, sTable, sFile, sDelimiter, sSQL, sUpload, sNull string
+	var sMap, sKeys cli.StringSlice
+	var bNoHeader bool
+
+	app := cli.NewApp()
+	app.Name = "diff"
+	app.Usage = "Compare a CSV file with a HANA table"
+	app.Version = "0.0.1"
+
+	app.Flags = []cli.Flag{
+		extract.ConfigFlag(&sCfg),
+		extract.ProfileFlag(&sProfile),
+		cli.StringFlag{
+			Name:        "table",
+			Usage:       "Table SCHEMA.TABLE",
+			Destination: &sTable,
+		},
+		cli.StringFlag{
+			Name:        "file, f",
+			Usage:       "CSV file to compare",
+			Destination: &sFile,
+		},
+		cli.StringSliceFlag{
+			Name:  "key, k",
+			Usage: "Key column identifying a row, repeat for more columns (default primary key of the table)",
+			Value: &sKeys,
+		},
+		cli.StringSliceFlag{
+			Name:  "map, m",
+			Usage: "Column mapping COLUMN=SOURCE, SOURCE is a header name or a position from 1. Repeat for each column, unmapped columns take the header of the same name",
+			Value: &sMap,
+		},
+		cli.BoolFlag{
+			Name:        "no-header",
+			Usage:       "The file has no header line, columns are mapped by position",
+			Destination: &bNoHeader,
+		},
+		cli.StringFlag{
+			Name:        "delimiter, d",
+			Usage:       "CSV delimiter: semicolon, comma, tab, pipe or a single character (default [save] delimiter in config)",
+			Destination: &sDelimiter,
+		},
+		cli.StringFlag{
+			Name:        "null",
+			Usage:       "Text read as NULL, ie \\N. Without it empty text equals NULL in nullable text columns",
+			Destination: &sNull,
+		},
+		cli.StringFlag{
+			Name:        "sql",
+			Usage:       "Write the insert, update and delete statements turning the table into the file",
+			Destination: &sSQL,
+		},
+		cli.StringFlag{
+			Name:        "upload",
+			Usage:       "Write the added and changed lines of the file, to load with --mode upsert",
+			Destination: &sUpload,
+		},
+	}
+
+	app.Action = func(c *cli.Context) error {
+		if sTable == "" || sFile == "" {
+			log.Fatal("You need to enter table and file")
+		}
+
+		j := load.DiffJob{
+			Table:     sTable,
+			File:      sFile,
+			Map:       sMap,
+			NoHeader:  bNoHeader,
+			Delimiter: sDelimiter,
+			Keys:      sKeys,
+			Profile:   sProfile,
+			SQL:       sSQL,
+			Upload:    sUpload,
+			Null:      sNull,
+		}
+		n, err := load.Diff(sCfg, j)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if n != 0 {
+			// like diff(1), differences exit with 1
+			return cli.NewExitError(fmt.Sprintf("%d rows differ", n), 1)
+		}
+		return nil
+	}
+
+	// init the program
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
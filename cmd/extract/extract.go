@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+	// cli
+	"github.com/urfave/cli"
+)
+
+func main() {
+	var o extract.Options
+	var sDef, sQuery, sParamsFile string
+	var sParams cli.StringSlice
+
+	app := cli.NewApp()
+	app.Name = "extract"
+	app.Usage = "Get table by definition file"
+	app.Version = "0.0.1"
+
+	app.Flags = extract.JoinFlags(o.Flags(), o.CompanyFlags(), o.ChunkFlags(), []cli.Flag{
+		cli.StringFlag{
+			Name:        "def, d",
+			Usage:       "Table definition file (see defs/)",
+			Destination: &sDef,
+		},
+		cli.StringFlag{
+			Name:        "query, q",
+			Usage:       "SQL file with {{name}} parameters, instead of --def",
+			Destination: &sQuery,
+		},
+		cli.StringSliceFlag{
+			Name:  "param, p",
+			Usage: "Query parameter as name=value, repeat for each parameter",
+			Value: &sParams,
+		},
+		cli.StringFlag{
+			Name:        "params",
+			Usage:       "ini file of parameter values, name = value, --param wins",
+			Destination: &sParamsFile,
+		},
+		cli.BoolFlag{
+			Name:        "delta",
+			Usage:       "Extract only rows changed since the last --delta run, see delta_sql in the definition",
+			Destination: &o.Delta,
+		},
+	})
+
+	app.Action = func(c *cli.Context) error {
+		if (sDef == "") == (sQuery == "") {
+			log.Fatal("You need to enter definition file or query file")
+		}
+
+		var t extract.Table
+		var err error
+		if sDef != "" {
+			t, err = extract.LoadTable(sDef)
+		} else {
+			t, err = extract.QueryTable(sQuery)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if o.Chunk != "" && sQuery != "" {
+			log.Fatal("--chunk needs a definition file with chunk_param")
+		}
+		t = o.Table(t)
+		if o.Delta && t.Delta == nil {
+			log.Fatal(sDef + sQuery + ": no delta_sql defined")
+		}
+		if o.Delta {
+			t.Params = t.Delta.Params
+		}
+
+		values, err := utils.ReadParams(sParamsFile, sParams)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var args []interface{}
+		if sQuery != "" {
+			var used map[string]string
+			if args, used, err = t.BindNamed(values); err != nil {
+				log.Fatal(err)
+			}
+			if unused := utils.UnusedParams(values, used); len(unused) > 0 {
+				log.Fatal(sQuery + ": no parameters " + strings.Join(unused, ", "))
+			}
+			if len(used) > 0 {
+				utils.WriteMsg("PARAMS: " + utils.ParamList(used))
+			}
+		} else if args, err = t.Args(values); err != nil {
+			log.Fatal(err)
+		}
+
+		if o.Delta {
+			if err := extract.RunDelta(o.Config, t, args...); err != nil {
+				log.Fatal(err)
+			}
+			return nil
+		}
+		if err := extract.Run(o.Config, t, args...); err != nil {
+			log.Fatal(err)
+		}
+		return nil
+	}
+
+	// init the program
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	// Register hdb driver.
+	_ "github.com/SAP/go-hdb/driver"
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+const (
+	// CommandTemplate -> template for a complete extract command
+	CommandTemplate = `package main
+
+import (
+	"log"
+	"os"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	// cli
+	"github.com/urfave/cli"
+)
+
+const (
+	{{.Var}}SQL = ` + "`" + `{{template "sql" .}}` + "`" + `
+)
+
+const (
+	cFile = "{{.File}}"
+)
+
+func main() {
+	var o extract.Options
+
+	app := cli.NewApp()
+	app.Name = "{{.Name}}"
+	app.Usage = "Get table {{.Name}}"
+	app.Version = "0.0.1"
+	app.Flags = {{if .DateColumn}}extract.JoinFlags(o.Flags(), o.RangeFlags()){{else}}o.Flags(){{end}}
+
+	app.Action = func(c *cli.Context) error {
+{{- if .DateColumn}}
+		if o.Start == "" || o.End == "" {
+			log.Fatal("You need to enter Start and End Date")
+		}
+{{end}}
+		t := o.Table(extract.Table{
+			Name:   app.Name,
+			SQL:    {{.Var}}SQL,
+			Output: cFile,
+		})
+		if err := o.Run(t{{if .DateColumn}}, o.Start, o.End{{end}}); err != nil {
+			log.Fatal(err)
+		}
+		return nil
+	}
+
+	// init the program
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+	// DefinitionTemplate -> template for a table definition file of cmd/extract
+	DefinitionTemplate = `; generated by gen_code_ddf from {{.Schema}}.{{.Table}}
+name = {{.Name}}
+output = {{.File}}
+{{- if .DateColumn}}
+params = start, end
+{{- end}}
+sql = """{{template "sql" .}}"""
+`
+
+	// SQLTemplate -> template for the select statement
+	SQLTemplate = `select
+{{range $i, $c := .Columns}}{{if $i}}, {{end}}{{$c}}
+{{end}}from {{.From}}
+{{- if .Client}}
+where mandt = $$client$$
+{{- end}}
+{{- if .DateColumn}}
+{{if .Client}}and{{else}}where{{end}} {{.DateColumn}} between ? and ?
+{{- end}}`
+)
+
+// genTable - Values for CommandTemplate and DefinitionTemplate
+type genTable struct {
+	Schema     string
+	Table      string
+	Name       string
+	Var        string
+	File       string
+	From       string
+	DateColumn string
+	// Client - Table has MANDT, the query selects the configured client only
+	Client  bool
+	Columns []string
+}
+
+// newGenTable - Prepare template values from catalog columns.
+// The configured schemas are written as placeholder so the generated query follows config.ini.
+func newGenTable(cfg utils.Config, schema, table, dateColumn string, cols []utils.Column) genTable {
+	g := genTable{
+		Schema: schema,
+		Table:  table,
+		Name:   strings.ToUpper(table),
+		File:   strings.ToLower(strings.Replace(table, "/", "", -1)),
+		From:   fromName(schema) + "." + fromName(table),
+	}
+	switch schema {
+	case utils.SchemaName(cfg.Schema):
+		g.From = utils.SchemaPlaceholder + "." + fromName(table)
+	case utils.SchemaName(cfg.ConsolSchema):
+		g.From = utils.ConsolSchemaPlaceholder + "." + fromName(table)
+	}
+	if dateColumn != "" {
+		g.DateColumn = utils.QuoteIdentifier(strings.ToUpper(dateColumn))
+	}
+
+	// ZEST_BLOCKH -> zestBlockh
+	for i, part := range strings.FieldsFunc(strings.ToLower(table), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		if i > 0 {
+			part = strings.ToUpper(part[:1]) + part[1:]
+		}
+		g.Var += part
+	}
+
+	for _, col := range cols {
+		g.Columns = append(g.Columns, utils.QuoteIdentifier(col.Name))
+		if col.Name == "MANDT" {
+			g.Client = true
+		}
+	}
+	return g
+}
+
+// fromName - Plain names are written lower case like the rest of the queries
+func fromName(s string) string {
+	q := utils.QuoteIdentifier(s)
+	if q == s {
+		return strings.ToLower(s)
+	}
+	return q
+}
+
+// generate - Execute CommandTemplate or DefinitionTemplate, commands are gofmt'ed
+func generate(g genTable, definition bool) ([]byte, error) {
+	text := CommandTemplate
+	if definition {
+		text = DefinitionTemplate
+	}
+	tmpl, err := template.New("main").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.New("sql").Parse(SQLTemplate); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, g); err != nil {
+		return nil, err
+	}
+	if definition {
+		return buf.Bytes(), nil
+	}
+	return format.Source(buf.Bytes())
+}
+
+// genFromCatalog - Read schema.table from HANA catalog and write the generated source to out, stdout if empty
+func genFromCatalog(sCfg, sProfile, sTable, sDateColumn, sOut string, definition bool) error {
+	schema, table, err := utils.SplitTableName(sTable)
+	if err != nil {
+		return err
+	}
+
+	// progress goes to stdout too, keep it clean when source is printed there
+	msg := func(s string) {
+		if sOut != "" {
+			utils.WriteMsg(s)
+		}
+	}
+
+	// read config file
+	msg("READ CONFIG")
+	cfg, err := utils.LoadProfile(sCfg, sProfile)
+	if err != nil {
+		return err
+	}
+
+	msg("OPEN HDB")
+	db, err := cfg.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	msg("READ CATALOG: " + schema + "." + table)
+	cols, err := utils.TableColumns(db, schema, table)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(newGenTable(cfg, schema, table, sDateColumn, cols), definition)
+	if err != nil {
+		return err
+	}
+
+	if sOut == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	msg("CREATE FILE: " + sOut)
+	return ioutil.WriteFile(sOut, src, 0644)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// AppendStringTemplate -> template for string type
+	AppendStringTemplate = "record = append(record, $)"
+
+	// AppendIntTemplate -> template for int type
+	AppendIntTemplate = "record = append(record, strconv.Itoa($))"
+
+	// AppendInt64Template -> template for int64 type
+	AppendInt64Template = "record = append(record, strconv.FormatInt($, 10))"
+
+	// AppendFloatTemplate -> template for float64 type
+	AppendFloatTemplate = "record = append(record, strconv.FormatFloat($, 'f', -1, 64))"
+
+	// AppendDecimalTemplate --> template for decimal type, utils.Decimal with 4 digits like utils.ConvertByteToStr
+	AppendDecimalTemplate = "record = append(record, $.StringFixed(4))"
+
+	// AppendBoolTemplate -> template for bool type
+	AppendBoolTemplate = "record = append(record, strconv.FormatBool($))"
+
+	// AppendBytesTemplate -> template for binary type
+	AppendBytesTemplate = "record = append(record, hex.EncodeToString($))"
+
+	// AppendDateTemplate -> template for date type
+	AppendDateTemplate = `record = append(record, $.Format("2006-01-02"))`
+
+	// AppendTimeTemplate -> template for time type
+	AppendTimeTemplate = `record = append(record, $.Format("15:04:05"))`
+
+	// AppendTimestampTemplate -> template for timestamp type
+	AppendTimestampTemplate = `record = append(record, $.Format("2006-01-02 15:04:05"))`
+
+	// AppendLobTemplate -> template for CLOB/NCLOB, utils.Lob
+	AppendLobTemplate = "record = append(record, $.String())"
+
+	// AppendBlobTemplate -> template for BLOB, utils.Lob
+	AppendBlobTemplate = "record = append(record, hex.EncodeToString($.Bytes()))"
+
+	// AppendNullTemplate -> wraps a template of a nullable type, # is the value
+	AppendNullTemplate = `if $.Valid {
+	#
+} else {
+	record = append(record, "")
+}`
+)
+
+// ddfType - Go type and append template for a DDF column type
+type ddfType struct {
+	GoType string
+	Append string
+	// Value - Field of the nullable type holding the value, empty when the type handles NULL itself
+	Value string
+}
+
+var (
+	// ddfTypes - Mapping of DDF types for NOT NULL columns.
+	// utils.Decimal has no Scan, so DECIMAL uses utils.NullDecimal and its promoted methods.
+	ddfTypes = map[string]ddfType{
+		"NVARCHAR":   {"string", AppendStringTemplate, ""},
+		"VARCHAR":    {"string", AppendStringTemplate, ""},
+		"NCHAR":      {"string", AppendStringTemplate, ""},
+		"CHAR":       {"string", AppendStringTemplate, ""},
+		"ALPHANUM":   {"string", AppendStringTemplate, ""},
+		"SHORTTEXT":  {"string", AppendStringTemplate, ""},
+		"TINYINT":    {"int", AppendIntTemplate, ""},
+		"SMALLINT":   {"int", AppendIntTemplate, ""},
+		"INTEGER":    {"int", AppendIntTemplate, ""},
+		"BIGINT":     {"int64", AppendInt64Template, ""},
+		"DOUBLE":     {"float64", AppendFloatTemplate, ""},
+		"REAL":       {"float64", AppendFloatTemplate, ""},
+		"DECIMAL":    {"utils.NullDecimal", AppendDecimalTemplate, ""},
+		"BOOLEAN":    {"bool", AppendBoolTemplate, ""},
+		"VARBINARY":  {"[]byte", AppendBytesTemplate, ""},
+		"BINARY":     {"[]byte", AppendBytesTemplate, ""},
+		"DATE":       {"time.Time", AppendDateTemplate, ""},
+		"TIME":       {"time.Time", AppendTimeTemplate, ""},
+		"TIMESTAMP":  {"time.Time", AppendTimestampTemplate, ""},
+		"SECONDDATE": {"time.Time", AppendTimestampTemplate, ""},
+		"NCLOB":      {"utils.Lob", AppendLobTemplate, ""},
+		"CLOB":       {"utils.Lob", AppendLobTemplate, ""},
+		"BLOB":       {"utils.Lob", AppendBlobTemplate, ""},
+	}
+
+	// ddfNullTypes - Mapping of DDF types for nullable columns
+	ddfNullTypes = map[string]ddfType{
+		"NVARCHAR":   {"sql.NullString", AppendStringTemplate, "String"},
+		"VARCHAR":    {"sql.NullString", AppendStringTemplate, "String"},
+		"NCHAR":      {"sql.NullString", AppendStringTemplate, "String"},
+		"CHAR":       {"sql.NullString", AppendStringTemplate, "String"},
+		"ALPHANUM":   {"sql.NullString", AppendStringTemplate, "String"},
+		"SHORTTEXT":  {"sql.NullString", AppendStringTemplate, "String"},
+		"TINYINT":    {"sql.NullInt64", AppendInt64Template, "Int64"},
+		"SMALLINT":   {"sql.NullInt64", AppendInt64Template, "Int64"},
+		"INTEGER":    {"sql.NullInt64", AppendInt64Template, "Int64"},
+		"BIGINT":     {"sql.NullInt64", AppendInt64Template, "Int64"},
+		"DOUBLE":     {"sql.NullFloat64", AppendFloatTemplate, "Float64"},
+		"REAL":       {"sql.NullFloat64", AppendFloatTemplate, "Float64"},
+		"DECIMAL":    {"utils.NullDecimal", AppendDecimalTemplate, "Decimal"},
+		"BOOLEAN":    {"sql.NullBool", AppendBoolTemplate, "Bool"},
+		"VARBINARY":  {"[]byte", AppendBytesTemplate, ""},
+		"BINARY":     {"[]byte", AppendBytesTemplate, ""},
+		"DATE":       {"utils.NullTime", AppendDateTemplate, "Time"},
+		"TIME":       {"utils.NullTime", AppendTimeTemplate, "Time"},
+		"TIMESTAMP":  {"utils.NullTime", AppendTimestampTemplate, "Time"},
+		"SECONDDATE": {"utils.NullTime", AppendTimestampTemplate, "Time"},
+		"NCLOB":      {"utils.Lob", AppendLobTemplate, ""},
+		"CLOB":       {"utils.Lob", AppendLobTemplate, ""},
+		"BLOB":       {"utils.Lob", AppendBlobTemplate, ""},
+	}
+)
+
+// ddfNullable - Third DDF column marks a nullable column
+func ddfNullable(s string) bool {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "X", "Y", "YES", "TRUE", "NULL":
+		return true
+	}
+	return false
+}
+
+// appendCode - Expand append template of t for variable name
+func (t ddfType) appendCode(name string) string {
+	if t.Value == "" {
+		return strings.Replace(t.Append, "$", name, -1)
+	}
+	value := strings.Replace(t.Append, "$", name+"."+t.Value, -1)
+	return strings.Replace(strings.Replace(AppendNullTemplate, "#", value, 1), "$", name, -1)
+}
+
+// readDDF - Read tab separated DDF file, the NULLABLE column is optional per line
+func readDDF(p string) ([][]string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// genFromDDF - Write var, scan and append fragments for DDF records (NAME, TYPE[, NULLABLE]) to w
+func genFromDDF(w io.Writer, rec [][]string) error {
+	var goTypes []string
+	vars := make(map[string][]string)
+	var scanVars []string
+	var appendString []string
+
+	if len(rec) == 0 {
+		return fmt.Errorf("no column found in DDF file")
+	}
+
+	for i := 0; i < len(rec); i++ {
+		if len(rec[i]) < 2 {
+			return fmt.Errorf("line %d: expected NAME and TYPE, got %q", i+1, rec[i])
+		}
+		name := strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1)
+		typeName := strings.ToUpper(strings.TrimSpace(rec[i][1]))
+
+		types := ddfTypes
+		if len(rec[i]) > 2 && ddfNullable(rec[i][2]) {
+			types = ddfNullTypes
+		}
+		t, ok := types[typeName]
+		if !ok {
+			return fmt.Errorf("line %d: unknown type %q for column %s", i+1, rec[i][1], rec[i][0])
+		}
+
+		if _, ok := vars[t.GoType]; !ok {
+			goTypes = append(goTypes, t.GoType)
+		}
+		vars[t.GoType] = append(vars[t.GoType], name)
+		scanVars = append(scanVars, "&"+name)
+		appendString = append(appendString, t.appendCode(name))
+	}
+
+	// variable code generation
+	fmt.Fprintln(w, "//----------------------- VAR ----------------------//")
+	for _, goType := range goTypes {
+		printVars(w, vars[goType], goType)
+	}
+
+	// scan code generation
+	fmt.Fprintln(w, "//----------------------- SCAN ----------------------//")
+	fmt.Fprintln(w, "if err := rows.Scan("+strings.Join(scanVars, ", ")+"); err != nil {")
+	fmt.Fprintln(w, "\tlog.Fatal(err)")
+	fmt.Fprintln(w, "}")
+
+	// assignment code generation
+	fmt.Fprintln(w, "//----------------------- APPEND ----------------------//")
+	for i := 0; i < len(appendString); i++ {
+		fmt.Fprintln(w, appendString[i])
+	}
+	return nil
+}
+
+// printVars - Write var declaration, MaxField names per line
+func printVars(w io.Writer, names []string, goType string) {
+	for i := 0; i < len(names); i += MaxField {
+		end := i + MaxField
+		if end > len(names) {
+			end = len(names)
+		}
+		fmt.Fprintln(w, "var "+strings.Join(names[i:end], ", ")+" "+goType)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"regexp"
+	"strings"
+	"testing"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+func TestGenFromDDF(t *testing.T) {
+	var rec [][]string
+	for typeName := range ddfTypes {
+		rec = append(rec, []string{"C_" + typeName, typeName})
+		rec = append(rec, []string{"N_" + typeName, typeName, "X"})
+	}
+
+	var buf bytes.Buffer
+	if err := genFromDDF(&buf, rec); err != nil {
+		t.Fatal(err)
+	}
+	// the fragments go into the body of a scan loop
+	src := "package p\n\nfunc f() {\n" + buf.String() + "}\n"
+	if _, err := format.Source([]byte(src)); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+	if !regexp.MustCompile(`(?m)^var .*\bn_decimal\b.* utils\.NullDecimal$`).MatchString(buf.String()) {
+		t.Errorf("nullable DECIMAL is not utils.NullDecimal:\n%s", buf.String())
+	}
+}
+
+func TestGenerateCommand(t *testing.T) {
+	cols := []utils.Column{{Name: "MANDT"}, {Name: "BUKRS"}, {Name: "/BIC/ZFIELD"}}
+	for _, dateColumn := range []string{"", "BUDAT"} {
+		g := newGenTable(utils.Config{Schema: "SAPABAP1"}, "SAPABAP1", "T001", dateColumn, cols)
+		// generate fails when the source does not parse
+		src, err := generate(g, false)
+		if err != nil {
+			t.Fatalf("date column %q: %v", dateColumn, err)
+		}
+		if strings.Contains(string(src), "bLog") {
+			t.Errorf("date column %q: generated command declares bLog", dateColumn)
+		}
+	}
+}
@@ -1,12 +1,12 @@
 package main
 
 import (
-	"fmt"
 	"log"
 	"os"
-	"strings"
 
-	"github.com/morxs/go-hana/utils"
+	// internal
+	"github.com/morxs/go-hana/extract"
+	// cli
 	"github.com/urfave/cli"
 )
 
@@ -22,17 +22,6 @@ const (
 
 	// MaxField -> maximum field to declare before newline applied (for easier code read)
 	MaxField = 5
-
-	// AppendStringTemplate -> template for string type
-	AppendStringTemplate = "record = append(record, $)"
-
-	// AppendIntTemplate -> template for int type
-	AppendIntTemplate = "record = append(record, strconv.Itoa($))"
-
-	// AppendDecimalTemplate --> template for decimal type
-	AppendDecimalTemplate = `neg, i = utils.DecodeDecimal($, &bi)
-z = utils.BigIntToFloat(neg, &bi, i)
-record = append(record, fmt.Sprintf("%.4f", z))`
 )
 
 /*
@@ -42,12 +31,13 @@ type struct DDL {
 }*/
 
 func main() {
-	var sCSVFile string
+	var sCSVFile, sCfg, sProfile, sTable, sDateColumn, sOut string
+	var bDefinition bool
 
 	app := cli.NewApp()
 	app.Name = "gen_code_ddf"
-	app.Usage = "Generate code by DDF"
-	app.Version = "0.0.2"
+	app.Usage = "Generate code by DDF or HANA catalog"
+	app.Version = "0.1.0"
 
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
@@ -56,120 +46,47 @@ func main() {
 			Usage:       "DDL (.csv, comma-seperated)",
 			Destination: &sCSVFile,
 		},
+		extract.ConfigFlag(&sCfg),
+		extract.ProfileFlag(&sProfile),
+		cli.StringFlag{
+			Name:        "table, t",
+			Usage:       "Generate from HANA catalog for SCHEMA.TABLE instead of DDF",
+			Destination: &sTable,
+		},
+		cli.StringFlag{
+			Name:        "where, w",
+			Usage:       "Date column to filter between --start and --end of the generated command",
+			Destination: &sDateColumn,
+		},
+		cli.BoolFlag{
+			Name:        "definition, d",
+			Usage:       "Generate table definition file for extract instead of a command",
+			Destination: &bDefinition,
+		},
+		cli.StringFlag{
+			Name:        "output, o",
+			Usage:       "Write generated source to file instead of stdout",
+			Destination: &sOut,
+		},
 	}
 	app.Action = func(c *cli.Context) error {
-
-		if sCSVFile == "" {
-			log.Fatal("No CSV file supplied. Please supply CSV file.")
-		}
-
-		//var rec [][]string
-		var RecString []string
-		var RecInt []string
-		var RecDecimal []string
-		var readCount int
-
-		ScanString := "if err := rows.Scan("
-
-		var AppendString []string
-
-		readCount = 1
-		rec, _ := utils.ReadCsv(sCSVFile, '\t')
-		for i := 0; i < len(rec); i++ {
-			switch strings.ToUpper(rec[i][1]) {
-			case "NVARCHAR", "VARCHAR":
-				RecString = append(RecString, strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1))
-				AppendString = append(AppendString, strings.Replace(AppendStringTemplate, "$", strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1), 1))
-			case "DECIMAL":
-				RecDecimal = append(RecDecimal, strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1))
-				AppendString = append(AppendString, strings.Replace(AppendDecimalTemplate, "$", strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1), 1))
-			case "INTEGER", "SMALLINT":
-				RecInt = append(RecInt, strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1))
-				AppendString = append(AppendString, strings.Replace(AppendIntTemplate, "$", strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1), 1))
-			}
-			fmt.Println(rec[i])
-			ScanString = ScanString + "&" + strings.Replace(strings.ToLower(rec[i][0]), "/", "", -1)
-			if readCount != len(rec) {
-				ScanString = ScanString + ", "
-			} else {
-				ScanString = ScanString + ")"
-			}
-			readCount++
-		}
-
-		// variable code generation
-		fmt.Println("//----------------------- VAR ----------------------//")
-		readCount = 1
-		for i := 0; i < len(RecString); i++ {
-			if readCount%MaxField == 0 {
-				//fmt.Print(strings.Replace(strings.ToLower(RecString[i]), "/", "", -1))
-				fmt.Print(RecString[i])
-				fmt.Println(" string")
-			} else {
-				if readCount%MaxField == 1 {
-					fmt.Print("var ")
-				}
-				//fmt.Print(strings.Replace(strings.ToLower(RecString[i]), "/", "", -1))
-				fmt.Print(RecString[i])
-				if readCount == len(RecString) {
-					fmt.Println(" string")
-				} else {
-					fmt.Print(", ")
-				}
+		if sTable != "" {
+			if err := genFromCatalog(sCfg, sProfile, sTable, sDateColumn, sOut, bDefinition); err != nil {
+				log.Fatal(err)
 			}
-			readCount++
+			return nil
 		}
 
-		readCount = 1
-		for i := 0; i < len(RecInt); i++ {
-			if readCount%MaxField == 0 {
-				//fmt.Print(strings.Replace(strings.ToLower(RecInt[i]), "/", "", -1))
-				fmt.Print(RecInt[i])
-				fmt.Println(" int")
-			} else {
-				if readCount%MaxField == 1 {
-					fmt.Print("var ")
-				}
-				//fmt.Print(strings.Replace(strings.ToLower(RecInt[i]), "/", "", -1))
-				fmt.Print(RecInt[i])
-				if readCount == len(RecInt) {
-					fmt.Println(" int")
-				} else {
-					fmt.Print(", ")
-				}
-			}
-			readCount++
+		if sCSVFile == "" {
+			log.Fatal("No CSV file supplied. Please supply CSV file.")
 		}
 
-		readCount = 1
-		for i := 0; i < len(RecDecimal); i++ {
-			if readCount%MaxField == 0 {
-				//fmt.Print(strings.Replace(strings.ToLower(RecDecimal[i]), "/", "", -1))
-				fmt.Print(RecDecimal[i])
-				fmt.Println(" []byte")
-			} else {
-				if readCount%MaxField == 1 {
-					fmt.Print("var ")
-				}
-				//fmt.Print(strings.Replace(strings.ToLower(RecDecimal[i]), "/", "", -1))
-				fmt.Print(RecDecimal[i])
-				if readCount == len(RecDecimal) {
-					fmt.Println(" []byte")
-				} else {
-					fmt.Print(", ")
-				}
-			}
-			readCount++
+		rec, err := readDDF(sCSVFile)
+		if err != nil {
+			log.Fatal(err)
 		}
-
-		// scan code generation
-		fmt.Println("//----------------------- SCAN ----------------------//")
-		fmt.Println(ScanString)
-
-		// assignment code generation
-		fmt.Println("//----------------------- APPEND ----------------------//")
-		for i := 0; i < len(AppendString); i++ {
-			fmt.Println(AppendString[i])
+		if err := genFromDDF(os.Stdout, rec); err != nil {
+			log.Fatal(err)
 		}
 
 		return nil
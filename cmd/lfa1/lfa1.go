@@ -1,26 +1,17 @@
 package main
 
 import (
-	"database/sql"
-	"encoding/csv"
-	"fmt"
 	"log"
-	"math/big"
 	"os"
-	"strings"
-
-	// Register hdb driver.
-	_ "github.com/SAP/go-hdb/driver"
-	// ini config
 
 	// internal
-	"github.com/morxs/go-hana/utils"
+	"github.com/morxs/go-hana/extract"
 	// cli
 	"github.com/urfave/cli"
 )
 
 const (
-	lfa1SQL = `select
+	lfa1Select = `select
 MANDT
 , LIFNR, LAND1, NAME1, NAME2, NAME3
 , NAME4, ORT01, ORT02, PFACH, PSTL2
@@ -46,13 +37,31 @@ MANDT
 , PSOFG, PSOIS, PSON1, PSON2, PSON3
 , PSOVN, PSOTL, PSOHS, PSOST, TRANSPORT_CHAIN
 , STAGING_TIME, SCHEDULING_TYPE, SUBMI_RELEVANT, ETHNIC, CATEGORY
-from sapabap1.lfa1
-where mandt = '777'
+from $$schema$$.lfa1`
+
+	lfa1SQL = lfa1Select + `
+where mandt = $$client$$
+and lifnr in (
+	select 
+	distinct lifnr
+	from $$schema$$.ekko
+	where mandt = $$client$$
+	and bedat between ? and ?
+	and bstyp = 'F'
+	and (bsart like '%20' or bsart like '%25')
+	and loekz = ''
+	and bukrs in 
+	($$coy$$)
+)`
+
+	// lfa1DeltaSQL - All vendors of the companies, --delta picks the changed ones by UPDAT, UPTIM
+	lfa1DeltaSQL = lfa1Select + `
+where mandt = $$client$$
 and lifnr in (
 	select 
 	distinct lifnr
-	from sapabap1.ekko
-	where bedat between ? and ?
+	from $$schema$$.ekko
+	where mandt = $$client$$
 	and bstyp = 'F'
 	and (bsart like '%20' or bsart like '%25')
 	and loekz = ''
@@ -66,280 +75,32 @@ const (
 )
 
 func main() {
-	var sCfg, sStartDate, sEndDate string
-	var bLog bool
+	var o extract.Options
 
 	app := cli.NewApp()
 	app.Name = "LFA1"
 	app.Usage = "Get table LFA1"
 	app.Version = "0.1.1"
-
-	app.Flags = []cli.Flag{
-		cli.StringFlag{
-			Name:        "config, c",
-			Value:       "config.ini",
-			Usage:       "Custom config file",
-			Destination: &sCfg,
-		},
-		cli.StringFlag{
-			Name:        "start, s",
-			Usage:       "Start Date (SAP format)",
-			Destination: &sStartDate,
-		},
-		cli.StringFlag{
-			Name:        "end, e",
-			Usage:       "End Date (SAP format)",
-			Destination: &sEndDate,
-		},
-		cli.BoolFlag{
-			Name:        "log, l",
-			Hidden:      true,
-			Usage:       "Enable logging. Log filename will be <query_filename>+.log",
-			Destination: &bLog,
-		},
-	}
+	app.Flags = extract.JoinFlags(o.Flags(), o.CompanyFlags(), o.RangeFlags(), o.DeltaFlags())
 
 	app.Action = func(c *cli.Context) error {
-		if sStartDate == "" || sEndDate == "" {
+		if !o.Delta && (o.Start == "" || o.End == "") {
 			log.Fatal("You need to enter Start and End Date")
 		}
-		// read config file
-		utils.WriteMsg("READ CONFIG")
-		hdbDsn, extension, err := utils.ReadConfig(sCfg)
-
-		// iniCfg, err := ini.Load(sCfg)
-		// if err != nil {
-		// 	utils.WriteMsg("CONFIG")
-		// 	log.Fatal(err)
-		// }
-		// iniSection := iniCfg.Section("server")
-		// iniKeyUsername := iniSection.Key("uid").String()
-		// iniKeyPassword := iniSection.Key("pwd").String()
-		// iniKeyHost := iniSection.Key("host").String()
-		// iniKeyPort := iniSection.Key("port").String()
-		// hdbDsn := "hdb://" + iniKeyUsername + ":" + iniKeyPassword + "@" + iniKeyHost + ":" + iniKeyPort
-
-		// iniSaveSection := iniCfg.Section("save")
-		// iniExtension := iniSaveSection.Key("extension").String()
-
-		utils.WriteMsg("OPEN HDB")
-		db, err := sql.Open(utils.DriverName, hdbDsn)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer db.Close()
-
-		if err := db.Ping(); err != nil {
-			log.Fatal(err)
-		}
-
-		// create file
-		filename := cFile + "." + extension
-		utils.WriteMsg("CREATE FILE: " + filename)
-		file, err := os.Create(filename)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer file.Close()
-
-		// try to query
-		utils.WriteMsg("QUERY")
-		sql := strings.Replace(lfa1SQL, "$$coy$$", utils.AfricaCoy, -1)
-		rows, err := db.Query(sql, sStartDate, sEndDate)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer rows.Close()
-
-		// prepare file
-		utils.WriteMsg("WRITE CSV")
-		w := csv.NewWriter(file)
-		w.Comma = ';'
-
-		fmt.Println(rows.Columns())
-
-		// add header to file
-		rs, _ := rows.Columns()
-		var rec []string
-		for i := 0; i < len(rs); i++ {
-			rec = append(rec, rs[i])
-		}
-		w.Write(rec)
-
-		for rows.Next() {
-			var mandt, lifnr, land1, name1, name2 string
-			var name3, name4, ort01, ort02, pfach string
-			var pstl2, pstlz, regio, sortl, stras string
-			var adrnr, mcod1, mcod2, mcod3, anred string
-			var bahns, bbbnr, bbsnr, begru, brsch string
-			var bubkz, datlt, dtams, dtaws, erdat string
-			var ernam, esrnr, konzs, ktokk, kunnr string
-			var lnrza, loevm, sperr, sperm, spras string
-			var stcd1, stcd2, stkza, stkzu, telbx string
-			var telf1, telf2, telfx, teltx, telx1 string
-			var xcpdk, xzemp, vbund, fiskn, stceg string
-			var stkzn, sperq, gbort, gbdat, sexkz string
-			var kraus, revdb, qssys, ktock, pfort string
-			var werks, ltsna, werkr, plkal, duefl string
-			var txjcd, sperz, scacd, sfrgr, lzone string
-			var xlfza, dlgrp, fityp, stcdt, regss string
-			var actss, stcd3, stcd4, stcd5, ipisp string
-			var taxbs, profs, stgdl, emnfr, lfurl string
-			var j_1kfrepre, j_1kftbus, j_1kftind, confs, updat string
-			var uptim, nodel, qssysdat, podkzb, fisku string
-			var stenr, carrier_conf, j_sc_currency, alc, pmt_office string
-			var psofg, psois, pson1, pson2, pson3 string
-			var psovn, psotl, psohs, psost, transport_chain string
-			var scheduling_type, submi_relevant, ethnic, category string
-			var j_sc_capital, staging_time []byte
-
-			if err := rows.Scan(&mandt, &lifnr, &land1, &name1, &name2, &name3, &name4, &ort01, &ort02, &pfach, &pstl2, &pstlz, &regio, &sortl, &stras, &adrnr, &mcod1, &mcod2, &mcod3, &anred, &bahns, &bbbnr, &bbsnr, &begru, &brsch, &bubkz, &datlt, &dtams, &dtaws, &erdat, &ernam, &esrnr, &konzs, &ktokk, &kunnr, &lnrza, &loevm, &sperr, &sperm, &spras, &stcd1, &stcd2, &stkza, &stkzu, &telbx, &telf1, &telf2, &telfx, &teltx, &telx1, &xcpdk, &xzemp, &vbund, &fiskn, &stceg, &stkzn, &sperq, &gbort, &gbdat, &sexkz, &kraus, &revdb, &qssys, &ktock, &pfort, &werks, &ltsna, &werkr, &plkal, &duefl, &txjcd, &sperz, &scacd, &sfrgr, &lzone, &xlfza, &dlgrp, &fityp, &stcdt, &regss, &actss, &stcd3, &stcd4, &stcd5, &ipisp, &taxbs, &profs, &stgdl, &emnfr, &lfurl, &j_1kfrepre, &j_1kftbus, &j_1kftind, &confs, &updat, &uptim, &nodel, &qssysdat, &podkzb, &fisku, &stenr, &carrier_conf, &j_sc_capital, &j_sc_currency, &alc, &pmt_office, &psofg, &psois, &pson1, &pson2, &pson3, &psovn, &psotl, &psohs, &psost, &transport_chain, &staging_time, &scheduling_type, &submi_relevant, &ethnic, &category); err != nil {
-				utils.WriteMsg("SCAN")
-				log.Fatal(err)
-			}
-
-			var bi big.Int
-			var z float64
-			var neg bool
-			var i int
-
-			var record []string
-
-			record = append(record, mandt)
-			record = append(record, lifnr)
-			record = append(record, land1)
-			record = append(record, name1)
-			record = append(record, name2)
-			record = append(record, name3)
-			record = append(record, name4)
-			record = append(record, ort01)
-			record = append(record, ort02)
-			record = append(record, pfach)
-			record = append(record, pstl2)
-			record = append(record, pstlz)
-			record = append(record, regio)
-			record = append(record, sortl)
-			record = append(record, stras)
-			record = append(record, adrnr)
-			record = append(record, mcod1)
-			record = append(record, mcod2)
-			record = append(record, mcod3)
-			record = append(record, anred)
-			record = append(record, bahns)
-			record = append(record, bbbnr)
-			record = append(record, bbsnr)
-			record = append(record, begru)
-			record = append(record, brsch)
-			record = append(record, bubkz)
-			record = append(record, datlt)
-			record = append(record, dtams)
-			record = append(record, dtaws)
-			record = append(record, erdat)
-			record = append(record, ernam)
-			record = append(record, esrnr)
-			record = append(record, konzs)
-			record = append(record, ktokk)
-			record = append(record, kunnr)
-			record = append(record, lnrza)
-			record = append(record, loevm)
-			record = append(record, sperr)
-			record = append(record, sperm)
-			record = append(record, spras)
-			record = append(record, stcd1)
-			record = append(record, stcd2)
-			record = append(record, stkza)
-			record = append(record, stkzu)
-			record = append(record, telbx)
-			record = append(record, telf1)
-			record = append(record, telf2)
-			record = append(record, telfx)
-			record = append(record, teltx)
-			record = append(record, telx1)
-			record = append(record, xcpdk)
-			record = append(record, xzemp)
-			record = append(record, vbund)
-			record = append(record, fiskn)
-			record = append(record, stceg)
-			record = append(record, stkzn)
-			record = append(record, sperq)
-			record = append(record, gbort)
-			record = append(record, gbdat)
-			record = append(record, sexkz)
-			record = append(record, kraus)
-			record = append(record, revdb)
-			record = append(record, qssys)
-			record = append(record, ktock)
-			record = append(record, pfort)
-			record = append(record, werks)
-			record = append(record, ltsna)
-			record = append(record, werkr)
-			record = append(record, plkal)
-			record = append(record, duefl)
-			record = append(record, txjcd)
-			record = append(record, sperz)
-			record = append(record, scacd)
-			record = append(record, sfrgr)
-			record = append(record, lzone)
-			record = append(record, xlfza)
-			record = append(record, dlgrp)
-			record = append(record, fityp)
-			record = append(record, stcdt)
-			record = append(record, regss)
-			record = append(record, actss)
-			record = append(record, stcd3)
-			record = append(record, stcd4)
-			record = append(record, stcd5)
-			record = append(record, ipisp)
-			record = append(record, taxbs)
-			record = append(record, profs)
-			record = append(record, stgdl)
-			record = append(record, emnfr)
-			record = append(record, lfurl)
-			record = append(record, j_1kfrepre)
-			record = append(record, j_1kftbus)
-			record = append(record, j_1kftind)
-			record = append(record, confs)
-			record = append(record, updat)
-			record = append(record, uptim)
-			record = append(record, nodel)
-			record = append(record, qssysdat)
-			record = append(record, podkzb)
-			record = append(record, fisku)
-			record = append(record, stenr)
-			record = append(record, carrier_conf)
-			neg, i = utils.DecodeDecimal(j_sc_capital, &bi)
-			z = utils.BigIntToFloat(neg, &bi, i)
-			record = append(record, fmt.Sprintf("%.4f", z))
-			record = append(record, j_sc_currency)
-			record = append(record, alc)
-			record = append(record, pmt_office)
-			record = append(record, psofg)
-			record = append(record, psois)
-			record = append(record, pson1)
-			record = append(record, pson2)
-			record = append(record, pson3)
-			record = append(record, psovn)
-			record = append(record, psotl)
-			record = append(record, psohs)
-			record = append(record, psost)
-			record = append(record, transport_chain)
-			neg, i = utils.DecodeDecimal(staging_time, &bi)
-			z = utils.BigIntToFloat(neg, &bi, i)
-			record = append(record, fmt.Sprintf("%.4f", z))
-			record = append(record, scheduling_type)
-			record = append(record, submi_relevant)
-			record = append(record, ethnic)
-			record = append(record, category)
-			w.Write(record)
-			//fmt.Println(record)
-		}
-		w.Flush()
 
-		if err := rows.Err(); err != nil {
-			utils.WriteMsg("ROWS")
+		t := o.Table(extract.Table{
+			Name:   app.Name,
+			SQL:    lfa1SQL,
+			Output: cFile,
+			Delta: &extract.Delta{
+				SQL:     lfa1DeltaSQL,
+				Columns: []string{"UPDAT", "UPTIM"},
+				Created: "ERDAT",
+			},
+		})
+		if err := o.Run(t, o.Start, o.End); err != nil {
 			log.Fatal(err)
 		}
-
 		return nil
 	}
 
@@ -348,5 +109,4 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/load"
+	// cli
+	"github.com/urfave/cli"
+)
+
+func main() {
+	var sCfg, sTarget, sTable, sFile, sDelimiter, sReject, sMode, sNull string
+	var sMap, sKeys cli.StringSlice
+	var iBatch int
+	var bNoHeader, bYes, bDryRun, bSnapshot bool
+
+	app := cli.NewApp()
+	app.Name = "load"
+	app.Usage = "Load a CSV file into a HANA table"
+	app.Version = "0.0.1"
+
+	app.Flags = []cli.Flag{
+		extract.ConfigFlag(&sCfg),
+		extract.TargetFlag(&sTarget),
+		cli.StringFlag{
+			Name:        "table",
+			Usage:       "Target table SCHEMA.TABLE",
+			Destination: &sTable,
+		},
+		cli.StringFlag{
+			Name:        "file, f",
+			Usage:       "CSV file to load",
+			Destination: &sFile,
+		},
+		cli.StringSliceFlag{
+			Name:  "map, m",
+			Usage: "Column mapping COLUMN=SOURCE, SOURCE is a header name or a position from 1. Repeat for each column, unmapped columns take the header of the same name",
+			Value: &sMap,
+		},
+		cli.BoolFlag{
+			Name:        "no-header",
+			Usage:       "The file has no header line, columns are mapped by position",
+			Destination: &bNoHeader,
+		},
+		cli.StringFlag{
+			Name:        "delimiter, d",
+			Usage:       "CSV delimiter: semicolon, comma, tab, pipe or a single character (default [save] delimiter in config)",
+			Destination: &sDelimiter,
+		},
+		cli.StringFlag{
+			Name:        "null",
+			Usage:       "Text read as NULL, ie \\N. Empty values are empty text in text columns and NULL in other nullable columns",
+			Destination: &sNull,
+		},
+		cli.IntFlag{
+			Name:        "batch, b",
+			Usage:       "Rows per bulk insert round trip (default [load] batch_size in config, else 1000)",
+			Destination: &iBatch,
+		},
+		cli.BoolFlag{
+			Name:        "yes, y",
+			Usage:       "Do not ask before writing to the target",
+			Destination: &bYes,
+		},
+		cli.BoolFlag{
+			Name:        "dry-run, n",
+			Usage:       "Validate the file against the table without writing",
+			Destination: &bDryRun,
+		},
+		cli.StringFlag{
+			Name:        "reject, r",
+			Usage:       "File for rejected rows and their reasons (default file with extension .rej)",
+			Destination: &sReject,
+		},
+		cli.StringFlag{
+			Name:        "mode",
+			Value:       "append",
+			Usage:       "append, truncate (delete all rows first), replace (delete the rows with the key values of the file first) or upsert (update rows by key, insert the rest)",
+			Destination: &sMode,
+		},
+		cli.StringSliceFlag{
+			Name:  "key, k",
+			Usage: "Key column of --mode replace or upsert, repeat for more columns. upsert defaults to the primary key",
+			Value: &sKeys,
+		},
+		cli.BoolFlag{
+			Name:        "snapshot",
+			Usage:       "Archive the rows of the table before writing, see snapshot list / restore",
+			Destination: &bSnapshot,
+		},
+	}
+
+	app.Action = func(c *cli.Context) error {
+		if sTable == "" || sFile == "" {
+			log.Fatal("You need to enter table and file")
+		}
+
+		j := load.Job{
+			Table:     sTable,
+			File:      sFile,
+			Map:       sMap,
+			NoHeader:  bNoHeader,
+			Delimiter: sDelimiter,
+			BatchSize: iBatch,
+			Target:    sTarget,
+			Yes:       bYes,
+			DryRun:    bDryRun,
+			Reject:    sReject,
+			Mode:      sMode,
+			Keys:      sKeys,
+			Null:      sNull,
+			Snapshot:  bSnapshot,
+		}
+		if err := load.Run(sCfg, j); err != nil {
+			log.Fatal(err)
+		}
+		return nil
+	}
+
+	// init the program
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
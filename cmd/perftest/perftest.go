@@ -1,22 +1,22 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	// Register hdb driver.
 	_ "github.com/SAP/go-hdb/driver"
 	// internals
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/perf"
 	"github.com/morxs/go-hana/utils"
 	//cli
 	"github.com/urfave/cli"
-
-	// ini config
-	"github.com/go-ini/ini"
 )
 
 /*
@@ -29,133 +29,321 @@ type argT struct {
 */
 
 func main() {
-	var sCfg, sSQL string
-	var bLog bool
+	var sCfg, sProfile, sJSON, sParamsFile string
+	var sSQL, sParams cli.StringSlice
+	var iIterations, iWarmup, iWorkers int
+	var dDuration, dRampUp time.Duration
+	var bLog, bExplain bool
+	var sRun, sBaseline string
+	var fThreshold float64
 
 	app := cli.NewApp()
 	app.Name = "perftest"
 	app.Usage = "Performance Test for HANA"
-	app.Version = "0.0.2"
+	app.Version = "0.0.5"
 
 	app.Flags = []cli.Flag{
+		extract.ConfigFlag(&sCfg),
+		extract.ProfileFlag(&sProfile),
+		cli.StringSliceFlag{
+			Name:  "query, q",
+			Usage: "SQL query file, repeat for more queries executed in turn",
+			Value: &sSQL,
+		},
+		cli.StringSliceFlag{
+			Name:  "param, p",
+			Usage: "Value of a {{name}} parameter of the query files as name=value, repeat for each parameter",
+			Value: &sParams,
+		},
 		cli.StringFlag{
-			Name:        "config, c",
-			Value:       "config.ini",
-			Usage:       "Custom config file",
-			Destination: &sCfg,
+			Name:        "params",
+			Usage:       "ini file of parameter values, name = value, --param wins",
+			Destination: &sParamsFile,
+		},
+		cli.IntFlag{
+			Name:        "iterations, n",
+			Value:       1,
+			Usage:       "Measured executions of every query over all workers",
+			Destination: &iIterations,
+		},
+		cli.IntFlag{
+			Name:        "warmup, w",
+			Usage:       "Executions of every query before measuring, to fill caches",
+			Destination: &iWarmup,
+		},
+		cli.IntFlag{
+			Name:        "workers",
+			Value:       1,
+			Usage:       "Concurrent connections executing the queries",
+			Destination: &iWorkers,
+		},
+		cli.DurationFlag{
+			Name:        "duration",
+			Usage:       "Keep executing for this long, ie 5m, instead of --iterations",
+			Destination: &dDuration,
+		},
+		cli.DurationFlag{
+			Name:        "ramp-up",
+			Usage:       "Start the workers spread over this time, ie 30s",
+			Destination: &dRampUp,
 		},
 		cli.StringFlag{
-			Name:        "query, q",
-			Usage:       "SQL query file",
-			Destination: &sSQL,
+			Name:        "json, j",
+			Usage:       "JSON report file (default first query file + .json)",
+			Destination: &sJSON,
+		},
+		cli.BoolFlag{
+			Name:        "explain, e",
+			Usage:       "Save EXPLAIN PLAN, M_SQL_PLAN_CACHE and M_EXPENSIVE_STATEMENTS of the queries next to the JSON report",
+			Destination: &bExplain,
 		},
 		cli.BoolFlag{
 			Name:        "log, l",
+			Usage:       "Enable logging. Log filename will be first query filename + .log, runs are compared from the results store",
 			Destination: &bLog,
 		},
 	}
 
 	app.Action = func(c *cli.Context) error {
-		if sSQL == "" {
-			log.Fatal("You need to enter Start and End Date also Created Start and End Date")
+		if len(sSQL) == 0 {
+			log.Fatal("You need to enter the query file")
+		}
+		if (dDuration <= 0 && iIterations < 1) || iWarmup < 0 || iWorkers < 1 {
+			log.Fatal("Iterations and workers must be at least 1 and warmup not negative")
 		}
 
 		// write to log if enable
-		var fLog os.File
-
 		if bLog {
 			// prepare log file
-			strLogFile := sSQL + ".log"
+			strLogFile := sSQL[0] + ".log"
 			fLog, err := os.OpenFile(strLogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 			if err != nil {
 				log.Fatal(err)
 			}
+			defer fLog.Close()
 			log.SetOutput(fLog)
-
 		}
-		defer fLog.Close()
 
 		// read config file
 		utils.WriteMsg("READ CONFIG")
-		iniCfg, err := ini.Load(sCfg)
+		cfg, err := utils.LoadProfile(sCfg, sProfile)
 		if err != nil {
-			utils.WriteMsg("CONFIG")
 			log.Fatal(err)
 		}
-		iniSection := iniCfg.Section("server")
-		iniKeyUsername := iniSection.Key("uid").String()
-		iniKeyPassword := iniSection.Key("pwd").String()
-		iniKeyHost := iniSection.Key("host").String()
-		iniKeyPort := iniSection.Key("port").String()
-		hdbDsn := "hdb://" + iniKeyUsername + ":" + iniKeyPassword + "@" + iniKeyHost + ":" + iniKeyPort
-
-		// utils.WriteMsg("OPEN SQL")
-		log.Println("OPEN SQL: " + sSQL)
-		fSQL, err := ioutil.ReadFile(sSQL)
+		if cfg.Profile != "" {
+			log.Println("PROFILE: " + cfg.Profile)
+		}
+
+		test := perf.LoadTest{
+			Workers:    iWorkers,
+			Warmup:     iWarmup,
+			Iterations: iIterations,
+			Duration:   dDuration,
+			RampUp:     dRampUp,
+			Timeout:    cfg.QueryTimeout,
+		}
+		values, err := utils.ReadParams(sParamsFile, sParams)
 		if err != nil {
 			log.Fatal(err)
 		}
-
-		sqlSQL := string(fSQL)
-
-		//fmt.Println(sqlSQL)
+		params := map[string]map[string]string{}
+		for _, f := range sSQL {
+			// utils.WriteMsg("OPEN SQL")
+			log.Println("OPEN SQL: " + f)
+			fSQL, err := ioutil.ReadFile(f)
+			if err != nil {
+				log.Fatal(err)
+			}
+			query, args, used, err := utils.BindNamed(string(fSQL), values)
+			if err != nil {
+				log.Fatal(f + ": " + err.Error())
+			}
+			// schemas and client from config, like the queries of extract
+			if query, args, err = cfg.Prepare(query, args, nil); err != nil {
+				log.Fatal(f + ": " + err.Error())
+			}
+			if len(used) > 0 {
+				log.Println("PARAMS: " + utils.ParamList(used))
+			}
+			params[f] = used
+			test.Queries = append(test.Queries, perf.Query{Name: f, SQL: query, Args: args, Params: used})
+		}
+		var used []map[string]string
+		for _, p := range params {
+			used = append(used, p)
+		}
+		if unused := utils.UnusedParams(values, used...); len(unused) > 0 {
+			log.Fatal("No query has the parameters " + strings.Join(unused, ", "))
+		}
+		if dDuration > 0 {
+			test.Iterations = 0
+		}
 
 		// utils.WriteMsg("OPEN HDB")
 		log.Println("OPEN HDB")
-		db, err := sql.Open(utils.DriverName, hdbDsn)
+		db, err := cfg.Open()
 		if err != nil {
 			log.Fatal(err)
 		}
 		defer db.Close()
 
-		if err := db.Ping(); err != nil {
+		store := perf.ResultsDir(cfg.ResultsDir)
+		start := time.Now()
+		run, err := store.NewRun(start)
+		if err != nil {
 			log.Fatal(err)
 		}
+		report := perf.Report{
+			Run:        run,
+			Time:       start,
+			Profile:    cfg.Profile,
+			Host:       cfg.Host + ":" + cfg.Port,
+			Workers:    iWorkers,
+			Warmup:     iWarmup,
+			Iterations: test.Iterations,
+			Duration:   dDuration.Seconds(),
+			RampUp:     dRampUp.Seconds(),
+		}
 
-		// create file
-		/*
-			utils.WriteMsg("CREATE FILE: " + File)
-			file, err := os.Create(File)
-			if err != nil {
-				fmt.Println(err)
-			}
-			defer file.Close()
-		*/
+		// try to query
+		if dDuration > 0 {
+			log.Printf("QUERY: %d workers for %v, %d warmup", iWorkers, dDuration, iWarmup)
+		} else {
+			log.Printf("QUERY: %d workers, %d iterations of every query, %d warmup", iWorkers, iIterations, iWarmup)
+		}
+		result := test.Run(db)
+		report.Queries = result.Queries
+		report.Total = result.Total
+		report.Elapsed = result.Elapsed.Seconds()
 
-		// log starttime
-		startTime := time.Now()
+		// utils.WriteMsg("DONE")
+		log.Println("DONE")
 
-		// try to query
-		// utils.WriteMsg("QUERY")
-		log.Println("QUERY")
-		rows, err := db.Query(sqlSQL)
-		if err != nil {
+		stats := report.Queries
+		if len(stats) > 1 {
+			stats = append(stats, report.Total)
+		}
+		if err := perf.WriteTable(os.Stdout, stats); err != nil {
 			log.Fatal(err)
 		}
-		defer rows.Close()
+		total := report.Total
+		log.Printf("Elapse: %v, %.2f qps, mean %.1fms, p95 %.1fms, first row mean %.1fms, %d errors",
+			result.Elapsed, total.QPS, total.Total.Mean, total.Total.P95, total.FirstRow.Mean, total.Errors)
 
-		for rows.Next() {
+		if sJSON == "" {
+			sJSON = sSQL[0] + ".json"
 		}
-
-		if err := rows.Err(); err != nil {
-			// utils.WriteMsg("ROWS")
-			log.Println("ROWS")
+		if bExplain {
+			log.Println("EXPLAIN")
+			var plans []perf.Plan
+			for _, q := range test.Queries {
+				plan := perf.Explain(db, q)
+				for _, e := range plan.Errors {
+					log.Printf("EXPLAIN %s: %s", q.Name, e)
+				}
+				plans = append(plans, plan)
+			}
+			report.Explain = strings.TrimSuffix(sJSON, filepath.Ext(sJSON)) + ".explain.json"
+			if err := perf.SavePlans(report.Explain, plans); err != nil {
+				log.Fatal(err)
+			}
+			log.Println("EXPLAIN: " + report.Explain)
+		}
+		if err := report.Save(sJSON); err != nil {
 			log.Fatal(err)
 		}
-
-		// log end time
-		endTime := time.Since(startTime)
-
-		// utils.WriteMsg("DONE")
-		log.Println("DONE")
-
-		fmt.Printf("Query took %v\n", endTime)
-		log.Println("Elapse: " + endTime.String())
+		log.Println("REPORT: " + sJSON)
+		if err := store.Append(perf.Records(report, params)); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("RESULTS: run " + run + " in " + store.Dir)
 		log.Println()
 
+		if total.Errors > 0 && total.Errors == total.Iterations {
+			return cli.NewExitError("every iteration failed", 1)
+		}
 		return nil
 	}
 
+	app.Commands = []cli.Command{
+		{
+			Name:  "compare",
+			Usage: "Compare the p95 of a run with the baseline, exit 1 if it regressed beyond the threshold",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:        "run",
+					Usage:       "Run to compare (default the latest)",
+					Destination: &sRun,
+				},
+				cli.StringFlag{
+					Name:        "baseline, b",
+					Usage:       "Compare with this run instead of the baseline set with perftest baseline",
+					Destination: &sBaseline,
+				},
+				cli.Float64Flag{
+					Name:        "threshold, t",
+					Value:       20,
+					Usage:       "Allowed increase of p95 in percent",
+					Destination: &fThreshold,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				store, err := perf.LoadStore(sCfg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				run, err := store.Run(sRun)
+				if err != nil {
+					log.Fatal(err)
+				}
+				var base map[string]perf.Record
+				if sBaseline != "" {
+					baseRun, err := store.Run(sBaseline)
+					if err != nil {
+						log.Fatal(err)
+					}
+					base = perf.RunBaseline(baseRun)
+				} else if base, err = store.Baselines(); err != nil {
+					log.Fatal(err)
+				}
+				cmps := perf.Compare(run, base, fThreshold)
+				if err := perf.WriteComparison(os.Stdout, cmps); err != nil {
+					log.Fatal(err)
+				}
+				regressed := 0
+				for _, cmp := range cmps {
+					if cmp.Regressed {
+						regressed++
+					}
+				}
+				if regressed > 0 {
+					return cli.NewExitError(fmt.Sprintf("%d of %d queries regressed more than %g%%", regressed, len(cmps), fThreshold), 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "baseline",
+			Usage:     "Make a run the baseline of its queries",
+			ArgsUsage: "[run] (default the latest)",
+			Action: func(c *cli.Context) error {
+				store, err := perf.LoadStore(sCfg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				run, err := store.Run(c.Args().First())
+				if err != nil {
+					log.Fatal(err)
+				}
+				if err := store.SetBaseline(run); err != nil {
+					log.Fatal(err)
+				}
+				utils.WriteMsg(fmt.Sprintf("BASELINE: run %s, %d queries", run[0].Run, len(run)))
+				return nil
+			},
+		},
+	}
+
 	// init the program
 	err := app.Run(os.Args)
 	if err != nil {
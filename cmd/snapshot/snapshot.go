@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/load"
+	"github.com/morxs/go-hana/utils"
+	// cli
+	"github.com/urfave/cli"
+)
+
+func main() {
+	var sCfg, sTarget, sTable string
+	var bYes bool
+
+	app := cli.NewApp()
+	app.Name = "snapshot"
+	app.Usage = "List, take and restore table snapshots"
+	app.Version = "0.0.1"
+
+	app.Flags = []cli.Flag{
+		extract.ConfigFlag(&sCfg),
+	}
+
+	app.Commands = []cli.Command{
+		{
+			Name:  "list",
+			Usage: "Show the history of the snapshot archive, oldest first",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:        "table",
+					Usage:       "Only snapshots of SCHEMA.TABLE",
+					Destination: &sTable,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				snaps, err := load.Snapshots(sCfg, sTable)
+				if err != nil {
+					log.Fatal(err)
+				}
+				for _, s := range snaps {
+					fmt.Printf("%s  %s  %s %s  %d rows  %s\n", s.ID, s.Time.Format("2006-01-02 15:04:05"), s.Profile, s.Host, s.Rows, s.Reason)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "take",
+			Usage: "Archive the current rows of a table",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:        "target, t",
+					Usage:       "Connection profile [server.<name>] of config to read from, required, restore writes to the same",
+					Destination: &sTarget,
+				},
+				cli.StringFlag{
+					Name:        "table",
+					Usage:       "Table SCHEMA.TABLE",
+					Destination: &sTable,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if sTable == "" {
+					log.Fatal("You need to enter table")
+				}
+				snap, err := load.TakeSnapshot(sCfg, sTarget, sTable)
+				if err != nil {
+					log.Fatal(err)
+				}
+				utils.WriteMsg(fmt.Sprintf("SNAPSHOT: %s, %d rows", snap.ID, snap.Rows))
+				return nil
+			},
+		},
+		{
+			Name:      "restore",
+			Usage:     "Replace the rows of the table with those of a snapshot, the current rows are archived first",
+			ArgsUsage: "<snapshot>",
+			Flags: []cli.Flag{
+				extract.TargetFlag(&sTarget),
+				cli.BoolFlag{
+					Name:        "yes, y",
+					Usage:       "Do not ask before writing to the target",
+					Destination: &bYes,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					log.Fatal("You need to enter the snapshot, see snapshot list")
+				}
+				if err := load.Restore(sCfg, sTarget, c.Args().First(), bYes); err != nil {
+					log.Fatal(err)
+				}
+				return nil
+			},
+		},
+	}
+
+	// init the program
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
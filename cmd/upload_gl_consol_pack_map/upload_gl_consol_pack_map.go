@@ -1,22 +1,22 @@
 package main
 
 import (
-	"database/sql"
-	"fmt"
 	"log"
 	"os"
+	"strconv"
 
-	// Register hdb driver.
-	_ "github.com/SAP/go-hdb/driver"
-	// ini config
-	"github.com/go-ini/ini"
 	// internal
+	"github.com/morxs/go-hana/consolpack"
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/load"
 	"github.com/morxs/go-hana/utils"
 	"github.com/urfave/cli"
 )
 
 func main() {
-	var sCfg, sCSVFile string
+	var sCfg, sTarget, sCSVFile, sReject, sMode string
+	var sKeys cli.StringSlice
+	var bYes, bDryRun, bNoSnapshot, bHeader bool
 
 	app := cli.NewApp()
 	app.Name = "upload_gl_consol_pack_map"
@@ -24,18 +24,50 @@ func main() {
 	app.Version = "0.0.1"
 
 	app.Flags = []cli.Flag{
-		cli.StringFlag{
-			Name:        "config, c",
-			Value:       "config.ini",
-			Usage:       "Custom config file",
-			Destination: &sCfg,
+		extract.ConfigFlag(&sCfg),
+		extract.TargetFlag(&sTarget),
+		cli.BoolFlag{
+			Name:        "yes, y",
+			Usage:       "Do not ask before writing to the target",
+			Destination: &bYes,
 		},
 		cli.StringFlag{
 			Name:        "file, f",
 			Value:       "ddl.csv",
-			Usage:       "DDL (.csv, comma-seperated)",
+			Usage:       "Mapping (.csv, semicolon-separated, no header)",
 			Destination: &sCSVFile,
 		},
+		cli.BoolFlag{
+			Name:        "header",
+			Usage:       "The file starts with the header line written by dl_gl_consol_pack_map",
+			Destination: &bHeader,
+		},
+		cli.BoolFlag{
+			Name:        "dry-run, n",
+			Usage:       "Validate the file against the table without writing",
+			Destination: &bDryRun,
+		},
+		cli.StringFlag{
+			Name:        "reject, r",
+			Usage:       "File for rejected rows and their reasons (default file with extension .rej)",
+			Destination: &sReject,
+		},
+		cli.StringFlag{
+			Name:        "mode",
+			Value:       "append",
+			Usage:       "append, truncate (delete all rows first), replace (delete the rows with the key values of the file first) or upsert (update rows by key, insert the rest)",
+			Destination: &sMode,
+		},
+		cli.StringSliceFlag{
+			Name:  "key, k",
+			Usage: "Key column of --mode replace or upsert, repeat for more columns. upsert defaults to the primary key",
+			Value: &sKeys,
+		},
+		cli.BoolFlag{
+			Name:        "no-snapshot",
+			Usage:       "Do not archive the current mapping before writing",
+			Destination: &bNoSnapshot,
+		},
 	}
 
 	app.Action = func(c *cli.Context) error {
@@ -43,75 +75,42 @@ func main() {
 			log.Fatal("No CSV file supplied. Please supply CSV file.")
 		}
 
-		// read config file
-		utils.WriteMsg("READ CONFIG")
-		iniCfg, err := ini.Load(sCfg)
-		if err != nil {
-			utils.WriteMsg("CONFIG")
-			log.Fatal(err)
+		j := load.Job{
+			Table:     consolpack.GLConsolPackMapTable,
+			File:      sCSVFile,
+			Delimiter: "semicolon",
+			Target:    sTarget,
+			Yes:       bYes,
+			DryRun:    bDryRun,
+			Reject:    sReject,
+			Mode:      sMode,
+			Keys:      sKeys,
+			Snapshot:  !bNoSnapshot,
 		}
-		iniSection := iniCfg.Section("server")
-		/*
-			fmt.Println(iniSection)
-			fmt.Println(iniSection.KeyStrings())
-			fmt.Println(iniSection.Key("uid").String())
-			fmt.Println(iniSection.GetKey("uid"))
-		*/
-		iniKeyUsername := iniSection.Key("uid").String()
-		iniKeyPassword := iniSection.Key("pwd").String()
-		iniKeyHost := iniSection.Key("host").String()
-		iniKeyHost = "10.11.1.53"
-		iniKeyPort := iniSection.Key("port").String()
-		hdbDsn := "hdb://" + iniKeyUsername + ":" + iniKeyPassword + "@" + iniKeyHost + ":" + iniKeyPort
-
-		utils.WriteMsg("OPEN HDB")
-		//fmt.Print("OPENDB...")
-		db, err := sql.Open(utils.DriverName, hdbDsn)
+		// the rows are GLConsolPackMap values in field order, NULL only in its sql.NullString fields
+		utils.WriteMsg("READ FILE: " + sCSVFile)
+		maps, err := consolpack.ReadGLConsolPackMaps(sCSVFile, bHeader)
 		if err != nil {
-			//fmt.Print("OPENDB")
-			log.Fatal(err)
-		}
-		defer db.Close()
-
-		if err := db.Ping(); err != nil {
 			log.Fatal(err)
 		}
-
-		stmt, err := db.Prepare("bulk insert into Z_WILMAR_CONSODB.GL_CONSOL_PACK_MAP values (?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
-
-		// baca file
-		rec, _ := utils.ReadCsv(sCSVFile, ';')
-
-		for i := 0; i < len(rec); i++ {
-			/*
-				if rec[i][10] == "" {
-					rec[i][10] = sql.NullString{}
-				}
-			*/
-			if _, err := stmt.Exec(
-				rec[i][0],
-				rec[i][1],
-				rec[i][2],
-				rec[i][3],
-				rec[i][4],
-				rec[i][5],
-				rec[i][6],
-				NewNullString(rec[i][7]),
-				NewNullString(rec[i][8]),
-				rec[i][9],
-				NewNullString(rec[i][10]),
-				rec[i][11],
-				rec[i][12],
-				rec[i][13]); err != nil {
+		j.Rows = make([][]interface{}, 0, len(maps))
+		for _, m := range maps {
+			values, err := utils.StructValues(m)
+			if err != nil {
 				log.Fatal(err)
 			}
+			j.Rows = append(j.Rows, values)
 		}
-
-		if _, err := stmt.Exec(); err != nil {
+		columns, err := utils.StructColumns(consolpack.GLConsolPackMap{})
+		if err != nil {
+			log.Fatal(err)
+		}
+		for i, column := range columns {
+			j.Map = append(j.Map, column+"="+strconv.Itoa(i+1))
+		}
+		if err := load.Run(sCfg, j); err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println("DONE")
-
 		return nil
 	}
 
@@ -121,13 +120,3 @@ func main() {
 		log.Fatal(err)
 	}
 }
-
-func NewNullString(s string) sql.NullString {
-	if len(s) == 0 {
-		return sql.NullString{}
-	}
-	return sql.NullString{
-		String: s,
-		Valid:  true,
-	}
-}
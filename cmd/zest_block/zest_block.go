@@ -1,18 +1,11 @@
 package main // import "github.com/morxs/go-hana"
 
 import (
-	"database/sql"
-	"encoding/csv"
-	"fmt"
 	"log"
 	"os"
 
-	// Register hdb driver.
-	_ "github.com/SAP/go-hdb/driver"
-	// ini config
-	"github.com/go-ini/ini"
 	// internal
-	"github.com/morxs/go-hana/utils"
+	"github.com/morxs/go-hana/extract"
 	// cli
 	"github.com/urfave/cli"
 )
@@ -63,166 +56,32 @@ const (
 	, AENAM
 	, AEDAT
 	, AEZET
-	from sapabap1.zest_block`
+	from $$schema$$.zest_block
+	where mandt = $$client$$`
 )
 
 const (
-	cFile = "zest_block.csv"
+	cFile = "zest_block"
 )
 
 func main() {
-	var sCfg string
-	var bLog bool
+	var o extract.Options
 
 	app := cli.NewApp()
 	app.Name = "ZEST_BLOCK"
 	app.Usage = "Get table ZEST_BLOCK"
 	app.Version = "0.1.1"
-
-	app.Flags = []cli.Flag{
-		cli.StringFlag{
-			Name:        "config, c",
-			Value:       "config.ini",
-			Usage:       "Custom config file",
-			Destination: &sCfg,
-		},
-		cli.BoolFlag{
-			Name:        "log, l",
-			Hidden:      true,
-			Usage:       "Enable logging. Log filename will be <query_filename>+.log",
-			Destination: &bLog,
-		},
-	}
+	app.Flags = o.Flags()
 
 	app.Action = func(c *cli.Context) error {
-
-		// read config file
-		utils.WriteMsg("READ CONFIG")
-		iniCfg, err := ini.Load(sCfg)
-		if err != nil {
-			utils.WriteMsg("CONFIG")
-			log.Fatal(err)
-		}
-		iniSection := iniCfg.Section("server")
-		iniKeyUsername := iniSection.Key("uid").String()
-		iniKeyPassword := iniSection.Key("pwd").String()
-		iniKeyHost := iniSection.Key("host").String()
-		iniKeyPort := iniSection.Key("port").String()
-		hdbDsn := "hdb://" + iniKeyUsername + ":" + iniKeyPassword + "@" + iniKeyHost + ":" + iniKeyPort
-
-		utils.WriteMsg("OPEN HDB")
-		db, err := sql.Open(utils.DriverName, hdbDsn)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer db.Close()
-
-		if err := db.Ping(); err != nil {
-			log.Fatal(err)
-		}
-
-		// create file
-		utils.WriteMsg("CREATE FILE: " + cFile)
-		file, err := os.Create(cFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer file.Close()
-
-		// try to query
-		utils.WriteMsg("QUERY")
-		rows, err := db.Query(zestBlockSQL)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer rows.Close()
-
-		// prepare file
-		utils.WriteMsg("WRITE CSV")
-		w := csv.NewWriter(file)
-		w.Comma = ';'
-
-		fmt.Println(rows.Columns())
-		// add header to file
-		rs, _ := rows.Columns()
-		var rec []string
-		for i := 0; i < len(rs); i++ {
-			rec = append(rec, rs[i])
-		}
-		w.Write(rec)
-
-		for rows.Next() {
-			var mandt, bukrs, estnr, divnr, block string
-			var kdatb, kdate, plblk, upblk, lcblk string
-			var lvchk, phase, lntyp, anln1, kunnr string
-			var lifnr, hgu, ablock, rdivnr, rblock string
-			var bname, rplnt, btype, tpgrp, seedo string
-			var sdpsy, yplan, byplan, initl, maint string
-			var scout, matre, tpalm, dimon, bmaint string
-			var bmatre, subgrp, rebgt, ernam, erdat string
-			var erzet, aenam, aedat, aezet string
-
-			if err := rows.Scan(&mandt, &bukrs, &estnr, &divnr, &block, &kdatb, &kdate, &plblk, &upblk, &lcblk, &lvchk, &phase, &lntyp, &anln1, &kunnr, &lifnr, &hgu, &ablock, &rdivnr, &rblock, &bname, &rplnt, &btype, &tpgrp, &seedo, &sdpsy, &yplan, &byplan, &initl, &maint, &scout, &matre, &tpalm, &dimon, &bmaint, &bmatre, &subgrp, &rebgt, &ernam, &erdat, &erzet, &aenam, &aedat, &aezet); err != nil {
-				utils.WriteMsg("SCAN")
-				log.Fatal(err)
-			}
-
-			var record []string
-			record = append(record, mandt)
-			record = append(record, bukrs)
-			record = append(record, estnr)
-			record = append(record, divnr)
-			record = append(record, block)
-			record = append(record, kdatb)
-			record = append(record, kdate)
-			record = append(record, plblk)
-			record = append(record, upblk)
-			record = append(record, lcblk)
-			record = append(record, lvchk)
-			record = append(record, phase)
-			record = append(record, lntyp)
-			record = append(record, anln1)
-			record = append(record, kunnr)
-			record = append(record, lifnr)
-			record = append(record, hgu)
-			record = append(record, ablock)
-			record = append(record, rdivnr)
-			record = append(record, rblock)
-			record = append(record, bname)
-			record = append(record, rplnt)
-			record = append(record, btype)
-			record = append(record, tpgrp)
-			record = append(record, seedo)
-			record = append(record, sdpsy)
-			record = append(record, yplan)
-			record = append(record, byplan)
-			record = append(record, initl)
-			record = append(record, maint)
-			record = append(record, scout)
-			record = append(record, matre)
-			record = append(record, tpalm)
-			record = append(record, dimon)
-			record = append(record, bmaint)
-			record = append(record, bmatre)
-			record = append(record, subgrp)
-			record = append(record, rebgt)
-			record = append(record, ernam)
-			record = append(record, erdat)
-			record = append(record, erzet)
-			record = append(record, aenam)
-			record = append(record, aedat)
-			record = append(record, aezet)
-
-			w.Write(record)
-			//fmt.Println(record)
-		}
-		w.Flush()
-
-		if err := rows.Err(); err != nil {
-			utils.WriteMsg("ROWS")
+		t := o.Table(extract.Table{
+			Name:   app.Name,
+			SQL:    zestBlockSQL,
+			Output: cFile,
+		})
+		if err := o.Run(t); err != nil {
 			log.Fatal(err)
 		}
-
 		return nil
 	}
 
@@ -231,5 +90,4 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-
 }
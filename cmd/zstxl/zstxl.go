@@ -1,19 +1,11 @@
 package main // import "github.com/morxs/go-hana"
 
 import (
-	"database/sql"
-	"encoding/csv"
-	"fmt"
 	"log"
 	"os"
-	"strings"
-
-	// Register hdb driver.
-	_ "github.com/SAP/go-hdb/driver"
-	// ini config
 
 	// internal
-	"github.com/morxs/go-hana/utils"
+	"github.com/morxs/go-hana/extract"
 	// cli
 	"github.com/urfave/cli"
 )
@@ -27,16 +19,18 @@ TDID,
 TDSPRAS,
 LINNO,
 TDLINE
-from sapabap1.zstxl
-where tdname in
+from $$schema$$.zstxl
+where mandt = $$client$$
+and tdname in
 (
 	select
 	concat(a.ebeln,a.ebelp) as "TDNAME"
-	from sapabap1.ekpo a
-	left join sapabap1.ekko b
+	from $$schema$$.ekpo a
+	left join $$schema$$.ekko b
 	on a.mandt = b.mandt
 	and a.ebeln = b.ebeln
-	where b.aedat between ? and ?
+	where a.mandt = $$client$$
+	and b.aedat between ? and ?
 	and b.bstyp = 'F'
 	and (b.bsart like '%20' or b.bsart like '%25')
 	and b.loekz = ''
@@ -49,150 +43,28 @@ const (
 	cFile = "zstxl"
 )
 
-func init() {
-}
-
 func main() {
-	var sCfg, sStartDate, sEndDate string
-	var bLog bool
+	var o extract.Options
 
 	app := cli.NewApp()
 	app.Name = "ZSTXL"
 	app.Usage = "Get table ZSTXL"
 	app.Version = "0.1.1"
-	app.Flags = []cli.Flag{
-		cli.StringFlag{
-			Name:        "config, c",
-			Value:       "config.ini",
-			Usage:       "Custom config file",
-			Destination: &sCfg,
-		},
-		cli.StringFlag{
-			Name:        "start, s",
-			Usage:       "Start Date (SAP format)",
-			Destination: &sStartDate,
-		},
-		cli.StringFlag{
-			Name:        "end, e",
-			Usage:       "End Date (SAP format)",
-			Destination: &sEndDate,
-		},
-		cli.BoolFlag{
-			Name:        "log, l",
-			Hidden:      true,
-			Usage:       "Enable logging. Log filename will be <query_filename>+.log",
-			Destination: &bLog,
-		},
-	}
+	app.Flags = extract.JoinFlags(o.Flags(), o.CompanyFlags(), o.ChunkFlags(), o.RangeFlags())
 
 	app.Action = func(c *cli.Context) error {
-		if sStartDate == "" || sEndDate == "" {
+		if o.Start == "" || o.End == "" {
 			log.Fatal("You need to enter Start and End Date")
 		}
-		// read config file
-		utils.WriteMsg("READ CONFIG")
-		hdbDsn, extension, err := utils.ReadConfig(sCfg)
-
-		// iniCfg, err := ini.ShadowLoad(sCfg) //Load(sCfg)
-		// if err != nil {
-		// 	utils.WriteMsg("CONFIG")
-		// 	log.Fatal(err)
-		// }
-		// iniSection := iniCfg.Section("server")
-		// iniKeyUsername := iniSection.Key("uid").String()
-		// iniKeyPassword := iniSection.Key("pwd").String()
-		// iniKeyHost := iniSection.Key("host").String()
-		// iniKeyPort := iniSection.Key("port").String()
-
-		// iniSaveSection := iniCfg.Section("save")
-		// iniExtension := iniSaveSection.Key("extension").String()
-
-		// // test out nested value
-		// iniOption := iniCfg.Section("option")
-		// iniCompany := iniOption.Key("company").ValueWithShadows()
-		// for _, ic := range iniCompany {
-		// 	fmt.Println(ic)
-		// }
-
-		// hdbDsn := "hdb://" + iniKeyUsername + ":" + iniKeyPassword + "@" + iniKeyHost + ":" + iniKeyPort
 
-		utils.WriteMsg("OPEN HDB")
-		db, err := sql.Open(utils.DriverName, hdbDsn)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer db.Close()
-
-		if err := db.Ping(); err != nil {
-			log.Fatal(err)
-		}
-
-		// create file
-		filename := cFile + "." + extension
-		utils.WriteMsg("CREATE FILE: " + filename)
-		file, err := os.Create(filename)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer file.Close()
-
-		// try to query
-		utils.WriteMsg("QUERY")
-		sql := strings.Replace(zstxlSQL, "$$coy$$", utils.AfricaCoy, -1)
-		rows, err := db.Query(sql, sStartDate, sEndDate)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer rows.Close()
-
-		// prepare file
-		utils.WriteMsg("WRITE CSV")
-		w := csv.NewWriter(file)
-		w.Comma = ';'
-
-		fmt.Println(rows.Columns())
-
-		// add header to file
-		rs, _ := rows.Columns()
-		var rec []string
-		for i := 0; i < len(rs); i++ {
-			rec = append(rec, rs[i])
-		}
-		w.Write(rec)
-
-		for rows.Next() {
-			var mandt, tdobject, tdname, tdid, tdspras, linno, tdline string
-			if err := rows.Scan(&mandt, &tdobject, &tdname, &tdid, &tdspras, &linno, &tdline); err != nil {
-				utils.WriteMsg("SCAN")
-				log.Fatal(err)
-			}
-
-			/*
-				var bi big.Int
-				var z float64
-				var neg bool
-				var i int
-			*/
-
-			var record []string
-
-			record = append(record, mandt)
-			record = append(record, tdobject)
-			record = append(record, tdname)
-			record = append(record, tdid)
-			record = append(record, tdspras)
-			record = append(record, linno)
+		t := o.Table(extract.Table{
+			Name:   app.Name,
+			SQL:    zstxlSQL,
+			Output: cFile,
 			// delete all \n
-			tdline = strings.Replace(tdline, "\n", " ", -1)
-			tdline = strings.Replace(tdline, "\r", " ", -1)
-			record = append(record, tdline)
-			w.Write(record)
-			//fmt.Println(record)
-		}
-		w.Flush()
-
-		if err := rows.Err(); err != nil {
-			utils.WriteMsg("ROWS")
+			SingleLine: true,
+		})
+		if err := o.Run(t, o.Start, o.End); err != nil {
 			log.Fatal(err)
 		}
 		return nil
@@ -0,0 +1,101 @@
+package consolpack
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+const (
+	// GLConsolPackMapTable - Table of GLConsolPackMap rows in the consolidation schema
+	GLConsolPackMapTable = "$$consol$$.GL_CONSOL_PACK_MAP"
+)
+
+// GLConsolPackMap - Row of GL_CONSOL_PACK_MAP, the fields in the column order of the
+// downloaded and uploaded files
+type GLConsolPackMap struct {
+	Year            string         `db:"YEAR"`
+	Group1          string         `db:"Group 1"`
+	Sort1           string         `db:"Sort 1"`
+	Group2          string         `db:"Group 2"`
+	Sort2           string         `db:"Sort 2"`
+	Group3          string         `db:"Group 3"`
+	Sort3           string         `db:"Sort 3"`
+	WorksheetID     sql.NullString `db:"Worksheet ID"`
+	GLAccount       sql.NullString `db:"GL Account"`
+	TransactionType string         `db:"Transaction Type"`
+	Sign            sql.NullString `db:"SIGN"`
+	ShortCode       string         `db:"Short Code"`
+	Remark          string         `db:"REMARK"`
+	ReportSheet     string         `db:"Report Sheet"`
+}
+
+// NewGLConsolPackMap - Row of the values of rec in field order. Empty values are NULL in the
+// sql.NullString fields and empty text in the others, like the upload always did.
+func NewGLConsolPackMap(rec []string) (GLConsolPackMap, error) {
+	var m GLConsolPackMap
+	err := utils.StructFromRecord(rec, &m)
+	return m, err
+}
+
+// ReadGLConsolPackMaps - Rows of semicolon separated file p, after a header line of the
+// columns, as written by dl_gl_consol_pack_map, if header
+func ReadGLConsolPackMaps(p string, header bool) ([]GLConsolPackMap, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.Comma = ';'
+	r.FieldsPerRecord = -1
+	var maps []GLConsolPackMap
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return maps, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header && line == 1 {
+			if err := checkHeader(rec); err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			continue
+		}
+		m, err := NewGLConsolPackMap(rec)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", p, line, err)
+		}
+		maps = append(maps, m)
+	}
+}
+
+// checkHeader - Error unless rec names the columns of GLConsolPackMap in field order
+func checkHeader(rec []string) error {
+	columns, err := utils.StructColumns(GLConsolPackMap{})
+	if err != nil {
+		return err
+	}
+	if len(rec) > 0 {
+		// Excel writes a byte order mark in front of UTF-8 files
+		rec[0] = strings.TrimPrefix(rec[0], "\ufeff")
+	}
+	if len(rec) != len(columns) {
+		return fmt.Errorf("header has %d columns, expected %s", len(rec), strings.Join(columns, ", "))
+	}
+	for i, c := range columns {
+		if !strings.EqualFold(strings.TrimSpace(rec[i]), c) {
+			return fmt.Errorf("header column %d is %q, expected %s", i+1, rec[i], c)
+		}
+	}
+	return nil
+}
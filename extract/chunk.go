@@ -0,0 +1,285 @@
+package extract
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+// chunk units, Table.Chunk
+const (
+	// ChunkDay - One part per day, start and end in SAP date format YYYYMMDD
+	ChunkDay = "day"
+	// ChunkMonth - One part per calendar month, start and end in YYYYMMDD
+	ChunkMonth = "month"
+	// ChunkPeriod - One part per SPMON period, start and end in YYYYMM, both query arguments get
+	// the period. Queries filtering on dates match no rows with a period, so a date range is refused.
+	ChunkPeriod = "period"
+)
+
+const (
+	sapDate   = "20060102"
+	sapPeriod = "200601"
+)
+
+// Chunk - One part of a chunked extract
+type Chunk struct {
+	Key   string `json:"key"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	File  string `json:"file"`
+	Rows  int    `json:"rows"`
+	Done  bool   `json:"done"`
+}
+
+// ParseChunk - Normalize a chunk unit, empty means no chunking
+func ParseChunk(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case ChunkDay:
+		return ChunkDay, nil
+	case ChunkMonth:
+		return ChunkMonth, nil
+	case ChunkPeriod, "spmon":
+		return ChunkPeriod, nil
+	}
+	return "", fmt.Errorf("unknown chunk %q, expected day, month or period", s)
+}
+
+// Chunks - Split the range start..end into parts of unit
+func Chunks(unit, start, end string) ([]Chunk, error) {
+	layout, label := sapDate, "YYYYMMDD"
+	if unit == ChunkPeriod {
+		layout, label = sapPeriod, "YYYYMM"
+		if len(start) == len(sapDate) || len(end) == len(sapDate) {
+			return nil, fmt.Errorf("chunk: period needs a SPMON range YYYYMM, %s - %s are dates, use day or month", start, end)
+		}
+	}
+	from, err := time.Parse(layout, start)
+	if err != nil {
+		return nil, fmt.Errorf("chunk: invalid start %q, expected %s", start, label)
+	}
+	to, err := time.Parse(layout, end)
+	if err != nil {
+		return nil, fmt.Errorf("chunk: invalid end %q, expected %s", end, label)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("chunk: end %s is before start %s", end, start)
+	}
+
+	var chunks []Chunk
+	for d := from; !d.After(to); {
+		var c Chunk
+		var next time.Time
+		switch unit {
+		case ChunkDay:
+			next = d.AddDate(0, 0, 1)
+			c = Chunk{Key: d.Format(sapDate), Start: d.Format(sapDate), End: d.Format(sapDate)}
+		case ChunkMonth:
+			next = time.Date(d.Year(), d.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			last := next.AddDate(0, 0, -1)
+			if last.After(to) {
+				last = to
+			}
+			c = Chunk{Key: d.Format(sapPeriod), Start: d.Format(sapDate), End: last.Format(sapDate)}
+		case ChunkPeriod:
+			next = d.AddDate(0, 1, 0)
+			c = Chunk{Key: d.Format(sapPeriod), Start: d.Format(sapPeriod), End: d.Format(sapPeriod)}
+		default:
+			return nil, fmt.Errorf("chunk: unknown unit %q", unit)
+		}
+		chunks = append(chunks, c)
+		d = next
+	}
+	return chunks, nil
+}
+
+// chunkState - Checkpoint of a chunked extract, saved after every completed chunk
+type chunkState struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Unit   string `json:"unit"`
+	// Query - Hash of SQL and query arguments, any change starts over
+	Query  string  `json:"query"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+// matches - True if s continues the same extract as o
+func (s chunkState) matches(o chunkState) bool {
+	if s.Name != o.Name || s.Format != o.Format || s.Unit != o.Unit || s.Query != o.Query || len(s.Chunks) != len(o.Chunks) {
+		return false
+	}
+	for i := range s.Chunks {
+		if s.Chunks[i].Key != o.Chunks[i].Key || s.Chunks[i].File != o.Chunks[i].File {
+			return false
+		}
+	}
+	return true
+}
+
+func loadChunkState(p string) (chunkState, error) {
+	var s chunkState
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(b, &s)
+	return s, err
+}
+
+// save - Write state to p, see utils.WriteFileAtomic
+func (s chunkState) save(p string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(p, func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
+
+// chunkManifest - List of part files written when the parts are not stitched
+type chunkManifest struct {
+	Name   string  `json:"name"`
+	Format string  `json:"format"`
+	Unit   string  `json:"unit"`
+	Rows   int     `json:"rows"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+// queryHash - Identify SQL and arguments of an extract
+func queryHash(t Table, args []interface{}) string {
+	h := sha1.New()
+	io.WriteString(h, t.SQL)
+	io.WriteString(h, strings.Join(t.Bukrs, ","))
+	for i, arg := range args {
+		if i == t.ChunkArg || i == t.ChunkArg+1 {
+			continue
+		}
+		fmt.Fprintf(h, "\x00%v", arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// runChunked - Extract t chunk by chunk into part files, resuming from the state file of a previous run
+func runChunked(db *sql.DB, cfg utils.Config, t Table, args []interface{}) error {
+	if t.ChunkArg < 0 || t.ChunkArg+1 >= len(args) {
+		return fmt.Errorf("chunk: %s has no start and end argument at %d", t.Name, t.ChunkArg)
+	}
+	start, ok1 := args[t.ChunkArg].(string)
+	end, ok2 := args[t.ChunkArg+1].(string)
+	if !ok1 || !ok2 {
+		return fmt.Errorf("chunk: start and end of %s must be text", t.Name)
+	}
+	chunks, err := Chunks(t.Chunk, start, end)
+	if err != nil {
+		return err
+	}
+
+	ext := Extension(t.Format, cfg)
+	dir := t.Output + ".parts"
+	for i := range chunks {
+		chunks[i].File = filepath.Join(dir, filepath.Base(t.Output)+"_"+chunks[i].Key+"."+ext)
+	}
+	state := chunkState{Name: t.Name, Format: t.Format, Unit: t.Chunk, Query: queryHash(t, args), Chunks: chunks}
+
+	statePath := t.Output + ".state"
+	if old, err := loadChunkState(statePath); err == nil && old.matches(state) {
+		utils.WriteMsg("RESUME: " + statePath)
+		state = old
+	} else if err == nil {
+		utils.WriteMsg("STATE CHANGED, START OVER: " + statePath)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	total := 0
+	for i := range state.Chunks {
+		c := &state.Chunks[i]
+		if _, err := os.Stat(c.File); c.Done && err == nil {
+			utils.WriteMsg("SKIP CHUNK " + c.Key + ": " + strconv.Itoa(c.Rows) + " rows")
+			total += c.Rows
+			continue
+		}
+
+		utils.WriteMsg("CHUNK " + c.Key + ": " + c.Start + " - " + c.End)
+		chunkArgs := append([]interface{}(nil), args...)
+		chunkArgs[t.ChunkArg], chunkArgs[t.ChunkArg+1] = c.Start, c.End
+		count, err := extractFile(db, cfg, t, c.File, nil, chunkArgs...)
+		if err != nil {
+			return fmt.Errorf("chunk %s: %v", c.Key, err)
+		}
+		c.Rows, c.Done = count, true
+		total += count
+		if err := state.save(statePath); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Stitch && t.Format != FormatParquet {
+		filename := t.Output + "." + ext
+		utils.WriteMsg("STITCH: " + filename)
+		if err := stitch(filename, t.Format, state.Chunks); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	} else {
+		filename := t.Output + ".manifest.json"
+		utils.WriteMsg("MANIFEST: " + filename)
+		m := chunkManifest{Name: t.Name, Format: t.Format, Unit: t.Chunk, Rows: total, Chunks: state.Chunks}
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+			return err
+		}
+	}
+	utils.WriteMsg("DONE: " + strconv.Itoa(total) + " rows")
+	return os.Remove(statePath)
+}
+
+// stitch - Concatenate text part files into p, csv and tsv keep the header of the first part only.
+// p is written atomically, see utils.WriteFileAtomic.
+func stitch(p, format string, chunks []Chunk) error {
+	return utils.WriteFileAtomic(p, func(file io.Writer) error {
+		w := bufio.NewWriter(file)
+		for i, c := range chunks {
+			part, err := os.Open(c.File)
+			if err != nil {
+				return err
+			}
+			r := bufio.NewReader(part)
+			if i > 0 && format != FormatJSONL {
+				if _, err := r.ReadString('\n'); err != nil && err != io.EOF {
+					part.Close()
+					return err
+				}
+			}
+			_, err = io.Copy(w, r)
+			part.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
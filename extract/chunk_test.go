@@ -0,0 +1,204 @@
+package extract
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		name       string
+		unit       string
+		start, end string
+		want       []Chunk
+	}{
+		{
+			name: "days",
+			unit: ChunkDay, start: "20190130", end: "20190201",
+			want: []Chunk{
+				{Key: "20190130", Start: "20190130", End: "20190130"},
+				{Key: "20190131", Start: "20190131", End: "20190131"},
+				{Key: "20190201", Start: "20190201", End: "20190201"},
+			},
+		},
+		{
+			name: "months cut to the range",
+			unit: ChunkMonth, start: "20191215", end: "20200210",
+			want: []Chunk{
+				{Key: "201912", Start: "20191215", End: "20191231"},
+				{Key: "202001", Start: "20200101", End: "20200131"},
+				{Key: "202002", Start: "20200201", End: "20200210"},
+			},
+		},
+		{
+			name: "periods",
+			unit: ChunkPeriod, start: "201911", end: "202001",
+			want: []Chunk{
+				{Key: "201911", Start: "201911", End: "201911"},
+				{Key: "201912", Start: "201912", End: "201912"},
+				{Key: "202001", Start: "202001", End: "202001"},
+			},
+		},
+		{
+			name: "single day",
+			unit: ChunkDay, start: "20190228", end: "20190228",
+			want: []Chunk{{Key: "20190228", Start: "20190228", End: "20190228"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Chunks(tt.unit, tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(chunks, tt.want) {
+				t.Errorf("chunks %+v, want %+v", chunks, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunksInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		unit       string
+		start, end string
+	}{
+		{"period of a date range", ChunkPeriod, "20190101", "20191231"},
+		{"period with a date end", ChunkPeriod, "201901", "20191231"},
+		{"day of a period range", ChunkDay, "201901", "201912"},
+		{"invalid date", ChunkMonth, "20190230", "20190331"},
+		{"end before start", ChunkDay, "20190102", "20190101"},
+		{"unknown unit", "week", "20190101", "20190131"},
+	}
+	for _, tt := range tests {
+		if chunks, err := Chunks(tt.unit, tt.start, tt.end); err == nil {
+			t.Errorf("%s: %+v, want an error", tt.name, chunks)
+		}
+	}
+}
+
+func TestParseChunk(t *testing.T) {
+	for in, want := range map[string]string{"": "", " Day ": ChunkDay, "MONTH": ChunkMonth, "spmon": ChunkPeriod, "period": ChunkPeriod} {
+		if got, err := ParseChunk(in); err != nil || got != want {
+			t.Errorf("ParseChunk(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseChunk("week"); err == nil {
+		t.Error("no error for week")
+	}
+}
+
+func TestChunkState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chunks, err := Chunks(ChunkMonth, "20190101", "20190331")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range chunks {
+		chunks[i].File = filepath.Join(dir, "part_"+chunks[i].Key+".csv")
+	}
+	state := chunkState{Name: "EKKO", Format: FormatCSV, Unit: ChunkMonth, Query: "hash", Chunks: chunks}
+	state.Chunks[0].Rows, state.Chunks[0].Done = 12, true
+
+	p := filepath.Join(dir, "out.state")
+	if err := state.save(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+	loaded, err := loadChunkState(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("loaded %+v, want %+v", loaded, state)
+	}
+
+	// a fresh state of the same extract continues the saved one, progress does not matter
+	fresh := chunkState{Name: "EKKO", Format: FormatCSV, Unit: ChunkMonth, Query: "hash", Chunks: append([]Chunk(nil), chunks...)}
+	fresh.Chunks[0].Rows, fresh.Chunks[0].Done = 0, false
+	if !loaded.matches(fresh) {
+		t.Error("saved state does not match the same extract")
+	}
+	changed := []func(s *chunkState){
+		func(s *chunkState) { s.Query = "other" },
+		func(s *chunkState) { s.Format = FormatJSONL },
+		func(s *chunkState) { s.Unit = ChunkDay },
+		func(s *chunkState) { s.Chunks = s.Chunks[:2] },
+		func(s *chunkState) { s.Chunks[1].File = "elsewhere.csv" },
+	}
+	for i, change := range changed {
+		s := fresh
+		s.Chunks = append([]Chunk(nil), fresh.Chunks...)
+		change(&s)
+		if loaded.matches(s) {
+			t.Errorf("change %d still matches", i)
+		}
+	}
+
+	if _, err := loadChunkState(filepath.Join(dir, "missing.state")); !os.IsNotExist(err) {
+		t.Errorf("missing state file: %v", err)
+	}
+}
+
+func TestStitch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stitch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	parts := []string{"A;B\n1;2\n", "A;B\n3;4\n", "A;B\n"}
+	var chunks []Chunk
+	for i, part := range parts {
+		p := filepath.Join(dir, "part"+strconv.Itoa(i+1))
+		if err := ioutil.WriteFile(p, []byte(part), 0644); err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, Chunk{File: p})
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatCSV, "A;B\n1;2\n3;4\n"},
+		{FormatJSONL, "A;B\n1;2\nA;B\n3;4\nA;B\n"},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(dir, "out."+tt.format)
+		if err := stitch(p, tt.format, chunks); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("%s stitched %q, want %q", tt.format, b, tt.want)
+		}
+	}
+
+	// a missing part leaves no output behind
+	p := filepath.Join(dir, "broken.csv")
+	missing := append(chunks, Chunk{File: filepath.Join(dir, "missing")})
+	if err := stitch(p, FormatCSV, missing); err == nil {
+		t.Error("no error for a missing part")
+	}
+	for _, f := range []string{p, p + ".tmp"} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a failed stitch", f)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package extract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+// column kinds, decided once per column from its database type name
+const (
+	// KindString - string value, also CLOB/NCLOB
+	KindString = iota
+	// KindInt - int64 value
+	KindInt
+	// KindFloat - float64 value
+	KindFloat
+	// KindDecimal - utils.Decimal value
+	KindDecimal
+	// KindBool - bool value
+	KindBool
+	// KindDate - time.Time value, date part only
+	KindDate
+	// KindTime - time.Time value, time part only
+	KindTime
+	// KindTimestamp - time.Time value
+	KindTimestamp
+	// KindBinary - []byte value, also BLOB
+	KindBinary
+)
+
+// Column - Result column of an extract
+type Column struct {
+	Name string
+	// Kind - Type of the values of this column, see KindString etc
+	Kind int
+	// DatabaseType - Type name as reported by HANA, ie NVARCHAR
+	DatabaseType string
+	// Precision, Scale - Only set for DECIMAL
+	Precision int
+	Scale     int
+	Nullable  bool
+}
+
+// ColumnKind - Kind of values for a HANA type name
+func ColumnKind(typeName string) int {
+	switch strings.ToUpper(typeName) {
+	case "TINYINT", "SMALLINT", "INTEGER", "BIGINT":
+		return KindInt
+	case "REAL", "DOUBLE":
+		return KindFloat
+	case "DECIMAL", "SMALLDECIMAL":
+		return KindDecimal
+	case "BOOLEAN":
+		return KindBool
+	case "DATE", "DAYDATE":
+		return KindDate
+	case "TIME", "SECONDTIME":
+		return KindTime
+	case "TIMESTAMP", "LONGDATE", "SECONDDATE":
+		return KindTimestamp
+	case "BINARY", "VARBINARY", "BLOB":
+		return KindBinary
+	}
+	return KindString
+}
+
+// FormatValue - Text representation of a non NULL value of col
+func FormatValue(col Column, v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case utils.Decimal:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return hex.EncodeToString(v)
+	case time.Time:
+		switch col.Kind {
+		case KindDate:
+			return v.Format("2006-01-02")
+		case KindTime:
+			return v.Format("15:04:05")
+		}
+		// fractions of a second only when there are some
+		return v.Format("2006-01-02 15:04:05.999999999")
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,147 @@
+package extract
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// quoting of CSV fields
+const (
+	// QuoteMinimal - Quote only fields containing delimiter, quote or line break
+	QuoteMinimal = iota
+	// QuoteAll - Quote every field
+	QuoteAll
+	// QuoteNone - Never quote, the value is written as is
+	QuoteNone
+)
+
+// ParseQuote - Quoting from config: minimal (default), all or none
+func ParseQuote(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "minimal":
+		return QuoteMinimal, nil
+	case "all":
+		return QuoteAll, nil
+	case "none":
+		return QuoteNone, nil
+	}
+	return 0, fmt.Errorf("invalid quote %q, expected minimal, all or none", s)
+}
+
+// CSVWriter - Write extract rows as delimiter separated text
+type CSVWriter struct {
+	// Comma - Field delimiter, ; by default
+	Comma rune
+	// Quote - QuoteMinimal, QuoteAll or QuoteNone
+	Quote int
+	// UseCRLF - End lines with \r\n instead of \n
+	UseCRLF bool
+	// Null - Text written for NULL values, ie "", \N or NULL
+	Null string
+	w    *bufio.Writer
+	cols []Column
+}
+
+// NewCSVWriter - CSVWriter on w with the default ; separator
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{Comma: ';', w: bufio.NewWriter(w)}
+}
+
+// WriteHeader - Write column names, the columns are kept to format the values
+func (w *CSVWriter) WriteHeader(cols []Column) error {
+	w.cols = cols
+	var rec []string
+	for _, col := range cols {
+		rec = append(rec, col.Name)
+	}
+	return w.writeRecord(rec, nil)
+}
+
+// Write - Format and write one row of values, nil is written as Null
+func (w *CSVWriter) Write(values []interface{}) error {
+	rec := make([]string, len(values))
+	null := make([]bool, len(values))
+	for i, v := range values {
+		if v == nil {
+			rec[i], null[i] = w.Null, true
+			continue
+		}
+		rec[i] = EscapeNull(FormatValue(w.cols[i], v), w.Null)
+	}
+	return w.writeRecord(rec, null)
+}
+
+// EscapeNull - field with one more \ in front if it reads as the NULL text null, also after
+// any number of \, so a text like \N is never taken for NULL. See UnescapeNull.
+func EscapeNull(field, null string) string {
+	if null != "" && strings.HasSuffix(field, null) && strings.Trim(field[:len(field)-len(null)], `\`) == "" {
+		return `\` + field
+	}
+	return field
+}
+
+// UnescapeNull - field written by EscapeNull for the NULL text null, without the \ added
+func UnescapeNull(field, null string) string {
+	if strings.HasPrefix(field, `\`) && EscapeNull(field[1:], null) == field {
+		return field[1:]
+	}
+	return field
+}
+
+// Close - Flush buffered rows
+func (w *CSVWriter) Close() error {
+	return w.w.Flush()
+}
+
+// writeRecord - Write fields of one line, NULL text is never quoted so \N stays recognizable
+func (w *CSVWriter) writeRecord(rec []string, null []bool) error {
+	for i, field := range rec {
+		if i > 0 {
+			if _, err := w.w.WriteRune(w.Comma); err != nil {
+				return err
+			}
+		}
+		if (null != nil && null[i]) || !w.needQuotes(field) {
+			if _, err := w.w.WriteString(field); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.w.WriteByte('"'); err != nil {
+			return err
+		}
+		if _, err := w.w.WriteString(strings.Replace(field, `"`, `""`, -1)); err != nil {
+			return err
+		}
+		if err := w.w.WriteByte('"'); err != nil {
+			return err
+		}
+	}
+	if w.UseCRLF {
+		_, err := w.w.WriteString("\r\n")
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// needQuotes - Same rules as encoding/csv for QuoteMinimal
+func (w *CSVWriter) needQuotes(field string) bool {
+	switch w.Quote {
+	case QuoteAll:
+		return true
+	case QuoteNone:
+		return false
+	}
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, w.Comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,257 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// DefaultDeltaState - Delta state store used when config has none
+	DefaultDeltaState = "delta.ini"
+
+	// DeltaOpColumn - First column of a delta file, DeltaInsert or DeltaUpdate
+	DeltaOpColumn = "DELTA_OP"
+	// DeltaInsert - Row created since the last run
+	DeltaInsert = "I"
+	// DeltaUpdate - Row changed since the last run, or inserted when the table has no creation column
+	DeltaUpdate = "U"
+)
+
+// Delta - Changed rows of a table, selected by a high-water mark
+type Delta struct {
+	// Key - Name in the delta state store, Table.Name if empty
+	Key string
+	// SQL - Query of all rows without any date window, ending in a where clause.
+	// The mark condition is appended with and.
+	SQL string
+	// Params - Parameter names of SQL, for definition files
+	Params []string
+	// Columns - High-water mark columns compared in order as written in SQL, ie a.AEDAT or AEDAT, AEZET
+	Columns []string
+	// Created - Creation date column telling inserts from updates, optional
+	Created string
+}
+
+// query - SQL and extra arguments selecting rows at or after mark, all rows if mark is empty.
+// The mark is inclusive: a date only mark can not tell what of that day was already extracted.
+func (d Delta) query(mark []string) (string, []interface{}) {
+	if len(mark) == 0 {
+		return d.SQL, nil
+	}
+
+	// (c1 > m1) or (c1 = m1 and c2 >= m2) ...
+	var or []string
+	var args []interface{}
+	for i := range d.Columns {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, d.Columns[j]+" = ?")
+			args = append(args, mark[j])
+		}
+		op := " > ?"
+		if i == len(d.Columns)-1 {
+			op = " >= ?"
+		}
+		and = append(and, d.Columns[i]+op)
+		args = append(args, mark[i])
+		or = append(or, "("+strings.Join(and, " and ")+")")
+	}
+	return d.SQL + "\nand (" + strings.Join(or, " or ") + ")", args
+}
+
+// DeltaStore - High-water marks of delta extracts, kept in an ini file with one section per table
+type DeltaStore struct {
+	p   string
+	cfg *ini.File
+}
+
+// LoadDeltaStore - Read delta state store p, a missing file is an empty store
+func LoadDeltaStore(p string) (*DeltaStore, error) {
+	if p == "" {
+		p = DefaultDeltaState
+	}
+	s := &DeltaStore{p: p, cfg: ini.Empty()}
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return s, nil
+	}
+	cfg, err := ini.Load(p)
+	if err != nil {
+		return nil, err
+	}
+	s.cfg = cfg
+	return s, nil
+}
+
+// Mark - High-water mark of key, nil if there was no successful run yet
+func (s *DeltaStore) Mark(key string) []string {
+	if !s.cfg.Section(key).HasKey("mark") {
+		return nil
+	}
+	return s.cfg.Section(key).Key("mark").Strings(",")
+}
+
+// SetMark - Record mark of key after a successful run and save the store
+func (s *DeltaStore) SetMark(key string, mark []string, rows int) error {
+	section := s.cfg.Section(key)
+	section.Key("mark").SetValue(strings.Join(mark, ", "))
+	section.Key("run").SetValue(time.Now().Format("2006-01-02 15:04:05"))
+	section.Key("rows").SetValue(strconv.Itoa(rows))
+
+	// through a temporary file so a crash never loses all marks
+	return utils.WriteFileAtomic(s.p, func(w io.Writer) error {
+		_, err := s.cfg.WriteTo(w)
+		return err
+	})
+}
+
+// RunDelta - Read config p and extract the rows of t changed since the last successful run
+// into a new delta file. args are bound to t.Delta.SQL.
+func RunDelta(p string, t Table, args ...interface{}) error {
+	if t.Delta == nil || t.Delta.SQL == "" || len(t.Delta.Columns) == 0 {
+		return fmt.Errorf("%s: no delta defined", t.Name)
+	}
+	key := t.Delta.Key
+	if key == "" {
+		key = t.Name
+	}
+
+	cfg, db, err := open(p, &t)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	store, err := LoadDeltaStore(cfg.DeltaState)
+	if err != nil {
+		return err
+	}
+	mark := store.Mark(key)
+	if len(mark) != 0 && len(mark) != len(t.Delta.Columns) {
+		return fmt.Errorf("%s: mark %v does not match delta columns %v", key, mark, t.Delta.Columns)
+	}
+	if mark == nil {
+		utils.WriteMsg("NO MARK, EXTRACT ALL ROWS OF " + key)
+	} else {
+		utils.WriteMsg("CHANGED SINCE: " + strings.Join(mark, " "))
+	}
+
+	query, markArgs := t.Delta.query(mark)
+	t.SQL = query
+	args = append(args, markArgs...)
+
+	dw := &deltaWriter{delta: t.Delta, mark: mark}
+	filename := t.Output + "_delta_" + time.Now().Format("20060102150405") + "." + Extension(t.Format, cfg)
+	count, err := extractFile(db, cfg, t, filename, func(w Writer) Writer {
+		dw.Writer = w
+		return dw
+	}, args...)
+	if err != nil {
+		return err
+	}
+
+	// nothing changed keeps the old mark
+	if dw.max != nil {
+		if err := store.SetMark(key, dw.max, count); err != nil {
+			return err
+		}
+		utils.WriteMsg("NEW MARK: " + strings.Join(dw.max, " "))
+	}
+	utils.WriteMsg("DONE: " + strconv.Itoa(count) + " rows, " +
+		strconv.Itoa(dw.inserted) + " inserted, " + strconv.Itoa(dw.updated) + " updated")
+	return nil
+}
+
+// deltaWriter - Prepend DeltaOpColumn to every row and track the highest mark written
+type deltaWriter struct {
+	Writer
+	delta *Delta
+	mark  []string
+	cols  []Column
+	// idx - Position of the mark columns, created is -1 without creation column
+	idx     []int
+	created int
+	max     []string
+
+	inserted, updated int
+}
+
+// WriteHeader - Find the mark columns and write the header with DeltaOpColumn in front
+func (w *deltaWriter) WriteHeader(cols []Column) error {
+	w.cols = cols
+	w.idx = make([]int, len(w.delta.Columns))
+	for i, name := range w.delta.Columns {
+		if w.idx[i] = columnIndex(cols, name); w.idx[i] < 0 {
+			return fmt.Errorf("delta: mark column %s not in query", name)
+		}
+	}
+	w.created = -1
+	if w.delta.Created != "" {
+		if w.created = columnIndex(cols, w.delta.Created); w.created < 0 {
+			return fmt.Errorf("delta: created column %s not in query", w.delta.Created)
+		}
+	}
+	return w.Writer.WriteHeader(append([]Column{{Name: DeltaOpColumn, Kind: KindString}}, cols...))
+}
+
+// Write - Write values with their DeltaInsert or DeltaUpdate flag
+func (w *deltaWriter) Write(values []interface{}) error {
+	mark := make([]string, len(w.idx))
+	for i, idx := range w.idx {
+		if values[idx] != nil {
+			mark[i] = FormatValue(w.cols[idx], values[idx])
+		}
+	}
+	if w.max == nil || compareMark(mark, w.max) > 0 {
+		w.max = mark
+	}
+
+	op := DeltaUpdate
+	switch {
+	case w.mark == nil:
+		// first run, everything is new
+		op = DeltaInsert
+	case w.created >= 0 && values[w.created] != nil:
+		if FormatValue(w.cols[w.created], values[w.created]) >= w.mark[0] {
+			op = DeltaInsert
+		}
+	}
+	if op == DeltaInsert {
+		w.inserted++
+	} else {
+		w.updated++
+	}
+	return w.Writer.Write(append([]interface{}{op}, values...))
+}
+
+// columnIndex - Position of column name in cols, -1 if missing. A table alias is ignored, a.AEDAT is AEDAT.
+func columnIndex(cols []Column, name string) int {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Trim(name, `"`)
+	for i, col := range cols {
+		if strings.EqualFold(col.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// compareMark - Compare marks column by column, SAP dates and times compare as text
+func compareMark(a, b []string) int {
+	for i := range a {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
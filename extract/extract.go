@@ -0,0 +1,315 @@
+package extract
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	// hdb driver, importing it also registers it
+	"github.com/SAP/go-hdb/driver"
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+const (
+	// DefaultExtension - Output extension used when config has none
+	DefaultExtension = "csv"
+)
+
+// Extension - File extension of format. [save] extension only applies to csv,
+// so switching format never leaves parquet data in a .csv file.
+func Extension(format string, cfg utils.Config) string {
+	if format == FormatCSV && cfg.Extension != "" {
+		return cfg.Extension
+	}
+	if format == FormatCSV {
+		return DefaultExtension
+	}
+	return format
+}
+
+// Run - Read config p, execute t and write the result into t.Output
+func Run(p string, t Table, args ...interface{}) error {
+	cfg, db, err := open(p, &t)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if t.Chunk != "" {
+		return runChunked(db, cfg, t, args)
+	}
+
+	count, err := extractFile(db, cfg, t, t.Output+"."+Extension(t.Format, cfg), nil, args...)
+	if err != nil {
+		return err
+	}
+	utils.WriteMsg("DONE: " + strconv.Itoa(count) + " rows")
+
+	return nil
+}
+
+// open - Read config p, complete t with its defaults and connect to HANA
+func open(p string, t *Table) (utils.Config, *sql.DB, error) {
+	// read config file
+	utils.WriteMsg("READ CONFIG")
+	cfg, err := utils.LoadProfile(p, t.Profile)
+	if err != nil {
+		return cfg, nil, err
+	}
+	if cfg.Profile != "" {
+		utils.WriteMsg("PROFILE: " + cfg.Profile)
+	}
+	if t.Format == "" {
+		t.Format = cfg.Format
+	}
+	if t.Format, err = ParseFormat(t.Format); err != nil {
+		return cfg, nil, err
+	}
+	if t.Chunk, err = ParseChunk(t.Chunk); err != nil {
+		return cfg, nil, err
+	}
+	if t.Scale == 0 {
+		t.Scale = cfg.Scale
+	}
+	if t.Timeout == 0 {
+		t.Timeout = cfg.QueryTimeout
+	}
+	if cfg.OutputDir != "" && !filepath.IsAbs(t.Output) {
+		if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+			return cfg, nil, err
+		}
+		t.Output = filepath.Join(cfg.OutputDir, t.Output)
+	}
+
+	// client and schemas of the command win over config
+	if t.Client != "" {
+		cfg.Client = t.Client
+	}
+	if t.Schema != "" {
+		cfg.Schema = t.Schema
+	}
+	if t.ConsolSchema != "" {
+		cfg.ConsolSchema = t.ConsolSchema
+	}
+	t.Client = cfg.Client
+	t.SQL = cfg.Query(t.SQL)
+	if t.Delta != nil {
+		delta := *t.Delta
+		delta.SQL = cfg.Query(delta.SQL)
+		t.Delta = &delta
+	}
+
+	utils.WriteMsg("OPEN HDB")
+	db, err := cfg.Open()
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	if t.usesCompanies() {
+		if t.Bukrs, err = cfg.Companies(t.Region, t.Bukrs); err != nil {
+			db.Close()
+			return cfg, nil, err
+		}
+		utils.WriteMsg("COMPANIES: " + strings.Join(t.Bukrs, ", "))
+		if err := cfg.ValidateCompanies(db, t.Bukrs); err != nil {
+			db.Close()
+			return cfg, nil, err
+		}
+	}
+	return cfg, db, nil
+}
+
+// extractFile - Extract t into filename. The rows go to a temporary file first,
+// so filename only exists once it is complete. wrap, if not nil, wraps the format writer.
+func extractFile(db *sql.DB, cfg utils.Config, t Table, filename string, wrap func(Writer) Writer, args ...interface{}) (int, error) {
+	// create file
+	utils.WriteMsg("CREATE FILE: " + filename)
+	var count int
+	err := utils.WriteFileAtomic(filename, func(file io.Writer) error {
+		// prepare file
+		w, err := NewWriter(file, t.Format, cfg)
+		if err != nil {
+			return err
+		}
+		if wrap != nil {
+			w = wrap(w)
+		}
+		count, err = Extract(db, t, w, args...)
+		return err
+	})
+	return count, err
+}
+
+// Extract - Execute t on db and write header and rows to w, returns number of rows written
+func Extract(db *sql.DB, t Table, w Writer, args ...interface{}) (int, error) {
+	// try to query
+	utils.WriteMsg("QUERY " + t.Name)
+	query, args, err := t.bind(args)
+	if err != nil {
+		return 0, err
+	}
+	ctx := context.Background()
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+		defer cancel()
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	utils.WriteMsg("WRITE " + strings.ToUpper(t.Format))
+	return WriteRows(rows, t, w)
+}
+
+// WriteRows - Write header and rows of rows to w and close w, returns number of rows written.
+// SingleLine and Scale of t apply, nothing is printed.
+func WriteRows(rows *sql.Rows, t Table, w Writer) (int, error) {
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	r := newRow(colTypes, t)
+
+	// add header to file
+	if err := w.WriteHeader(r.cols); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(r.dest...); err != nil {
+			return count, err
+		}
+		if err := w.Write(r.values()); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, w.Close()
+}
+
+// row - Scan destinations for one result row, every destination accepts NULL
+type row struct {
+	cols       []Column
+	dest       []interface{}
+	singleLine bool
+	scale      int
+}
+
+func newRow(colTypes []*sql.ColumnType, t Table) *row {
+	r := &row{
+		cols:       make([]Column, len(colTypes)),
+		dest:       make([]interface{}, len(colTypes)),
+		singleLine: t.SingleLine,
+		scale:      t.Scale,
+	}
+	for i, ct := range colTypes {
+		typeName := strings.ToUpper(ct.DatabaseTypeName())
+		col := Column{
+			Name:         ct.Name(),
+			Kind:         ColumnKind(typeName),
+			DatabaseType: typeName,
+			Nullable:     true,
+		}
+		if nullable, ok := ct.Nullable(); ok {
+			col.Nullable = nullable
+		}
+		if precision, scale, ok := ct.DecimalSize(); ok {
+			col.Precision, col.Scale = int(precision), int(scale)
+		}
+		if col.Kind == KindDecimal && t.Scale > 0 {
+			// keep room for the integer digits when more decimals are written
+			if col.Precision > 0 && t.Scale > col.Scale && col.Scale <= col.Precision {
+				col.Precision += t.Scale - col.Scale
+			}
+			col.Scale = t.Scale
+		}
+		r.cols[i] = col
+
+		switch {
+		case typeName == "CLOB" || typeName == "NCLOB" || typeName == "BLOB":
+			r.dest[i] = new(utils.Lob)
+		case col.Kind == KindInt:
+			r.dest[i] = new(sql.NullInt64)
+		case col.Kind == KindFloat:
+			r.dest[i] = new(sql.NullFloat64)
+		case col.Kind == KindDecimal:
+			r.dest[i] = new(utils.NullDecimal)
+		case col.Kind == KindBool:
+			r.dest[i] = new(sql.NullBool)
+		case col.Kind == KindDate || col.Kind == KindTime || col.Kind == KindTimestamp:
+			r.dest[i] = new(utils.NullTime)
+		case col.Kind == KindBinary:
+			r.dest[i] = new(driver.NullBytes)
+		default:
+			r.dest[i] = new(sql.NullString)
+		}
+	}
+	return r
+}
+
+// values - Scanned values as string, int64, float64, utils.Decimal, bool, time.Time or []byte, nil for NULL
+func (r *row) values() []interface{} {
+	values := make([]interface{}, len(r.dest))
+	for i, d := range r.dest {
+		switch v := d.(type) {
+		case *sql.NullString:
+			if v.Valid {
+				values[i] = r.text(v.String)
+			}
+		case *sql.NullInt64:
+			if v.Valid {
+				values[i] = v.Int64
+			}
+		case *sql.NullFloat64:
+			if v.Valid {
+				values[i] = v.Float64
+			}
+		case *utils.NullDecimal:
+			if v.Valid && r.scale > 0 {
+				values[i] = v.Round(r.scale)
+			} else if v.Valid {
+				values[i] = v.Decimal
+			}
+		case *sql.NullBool:
+			if v.Valid {
+				values[i] = v.Bool
+			}
+		case *utils.NullTime:
+			if v.Valid {
+				values[i] = v.Time
+			}
+		case *driver.NullBytes:
+			if v.Valid {
+				values[i] = v.Bytes
+			}
+		case *utils.Lob:
+			if v.Valid && r.cols[i].Kind == KindBinary {
+				values[i] = append([]byte(nil), v.Bytes()...)
+			} else if v.Valid {
+				values[i] = r.text(v.String())
+			}
+		}
+	}
+	return values
+}
+
+func (r *row) text(s string) string {
+	if r.singleLine {
+		s = strings.Replace(s, "\n", " ", -1)
+		s = strings.Replace(s, "\r", " ", -1)
+	}
+	return s
+}
@@ -0,0 +1,178 @@
+package extract
+
+import (
+	// internal
+	"github.com/morxs/go-hana/utils"
+	// cli
+	"github.com/urfave/cli"
+)
+
+// Options - Command line settings shared by the table commands, see Flags
+type Options struct {
+	// Config - config.ini of the command
+	Config  string
+	Profile string
+	Format  string
+	Client  string
+	Schema  string
+	// ConsolSchema - Consolidation schema replacing $$consol$$
+	ConsolSchema string
+	// Region and Bukrs - Company codes bound to $$coy$$
+	Region string
+	Bukrs  string
+	Chunk  string
+	// Start and End - Date range of the query, in SAP format
+	Start string
+	End   string
+	Delta bool
+}
+
+// ConfigFlag - config file flag with default config.ini
+func ConfigFlag(dest *string) cli.Flag {
+	return cli.StringFlag{
+		Name:        "config, c",
+		Value:       "config.ini",
+		Usage:       "Custom config file",
+		Destination: dest,
+	}
+}
+
+// ProfileFlag - Connection profile to read from
+func ProfileFlag(dest *string) cli.Flag {
+	return cli.StringFlag{
+		Name:        "profile",
+		Usage:       "Connection profile [server.<name>] of config (default $HANA_PROFILE or [server])",
+		Destination: dest,
+	}
+}
+
+// TargetFlag - Connection profile to write to, see utils.LoadTarget
+func TargetFlag(dest *string) cli.Flag {
+	return cli.StringFlag{
+		Name:        "target, t",
+		Usage:       "Connection profile [server.<name>] of config to write to, required",
+		Destination: dest,
+	}
+}
+
+// JoinFlags - One flag list of groups
+func JoinFlags(groups ...[]cli.Flag) []cli.Flag {
+	var flags []cli.Flag
+	for _, g := range groups {
+		flags = append(flags, g...)
+	}
+	return flags
+}
+
+// Flags - config, profile, format, client and schema, the flags of every table command
+func (o *Options) Flags() []cli.Flag {
+	return []cli.Flag{
+		ConfigFlag(&o.Config),
+		ProfileFlag(&o.Profile),
+		cli.StringFlag{
+			Name:        "format, f",
+			Usage:       "Output format: csv, tsv, jsonl or parquet (default [save] format in config)",
+			Destination: &o.Format,
+		},
+		cli.StringFlag{
+			Name:        "client",
+			Usage:       "SAP client (MANDT) (default [server] client in config)",
+			Destination: &o.Client,
+		},
+		cli.StringFlag{
+			Name:        "schema",
+			Usage:       "SAP schema (default [server] schema in config)",
+			Destination: &o.Schema,
+		},
+	}
+}
+
+// ConsolFlags - consol-schema, for queries of the consolidation schema $$consol$$
+func (o *Options) ConsolFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "consol-schema",
+			Usage:       "Consolidation schema (default [server] consol_schema in config)",
+			Destination: &o.ConsolSchema,
+		},
+	}
+}
+
+// CompanyFlags - region and bukrs, for queries with $$coy$$
+func (o *Options) CompanyFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "region, r",
+			Usage:       "Company group of config.ini [region] (default [region] default)",
+			Destination: &o.Region,
+		},
+		cli.StringFlag{
+			Name:        "bukrs, b",
+			Usage:       "Company codes separated by comma, instead of --region",
+			Destination: &o.Bukrs,
+		},
+	}
+}
+
+// ChunkFlags - chunk, for queries of a date range
+func (o *Options) ChunkFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "chunk, k",
+			Usage:       "Split the date range into day or month parts, or a SPMON range YYYYMM into period parts, a re-run resumes after the last finished part",
+			Destination: &o.Chunk,
+		},
+	}
+}
+
+// RangeFlags - start and end of the date range
+func (o *Options) RangeFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "start, s",
+			Usage:       "Start Date (SAP format)",
+			Destination: &o.Start,
+		},
+		cli.StringFlag{
+			Name:        "end, e",
+			Usage:       "End Date (SAP format)",
+			Destination: &o.End,
+		},
+	}
+}
+
+// DeltaFlags - delta, for tables with a Delta query
+func (o *Options) DeltaFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:        "delta",
+			Usage:       "Extract only rows changed since the last --delta run into a delta file, no dates needed",
+			Destination: &o.Delta,
+		},
+	}
+}
+
+// Table - t with the settings of o, format and chunk only if given
+func (o Options) Table(t Table) Table {
+	t.Profile = o.Profile
+	t.Client = o.Client
+	t.Schema = o.Schema
+	t.ConsolSchema = o.ConsolSchema
+	t.Region = o.Region
+	t.Bukrs = utils.SplitList(o.Bukrs)
+	if o.Format != "" {
+		t.Format = o.Format
+	}
+	if o.Chunk != "" {
+		t.Chunk = o.Chunk
+	}
+	return t
+}
+
+// Run - RunDelta t with --delta, the delta query of a table command takes no args, otherwise Run it with args
+func (o Options) Run(t Table, args ...interface{}) error {
+	if o.Delta {
+		return RunDelta(o.Config, t)
+	}
+	return Run(o.Config, t, args...)
+}
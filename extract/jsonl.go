@@ -0,0 +1,83 @@
+package extract
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+// JSONLWriter - Write extract rows as JSON Lines, one object per row keyed by column name
+type JSONLWriter struct {
+	w    *bufio.Writer
+	cols []Column
+	// names - JSON encoded column names, encoded once
+	names [][]byte
+}
+
+// NewJSONLWriter - JSONLWriter on w
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader - Keep the columns, JSON Lines has no header line
+func (w *JSONLWriter) WriteHeader(cols []Column) error {
+	w.cols = cols
+	w.names = make([][]byte, len(cols))
+	for i, col := range cols {
+		name, err := json.Marshal(col.Name)
+		if err != nil {
+			return err
+		}
+		w.names[i] = name
+	}
+	return nil
+}
+
+// Write - Write one row as JSON object in column order, nil is written as null
+func (w *JSONLWriter) Write(values []interface{}) error {
+	buf := []byte{'{'}
+	for i, v := range values {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, w.names[i]...)
+		buf = append(buf, ':')
+		value, err := w.value(w.cols[i], v)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, value...)
+	}
+	buf = append(buf, '}', '\n')
+	_, err := w.w.Write(buf)
+	return err
+}
+
+// Close - Flush buffered rows
+func (w *JSONLWriter) Close() error {
+	return w.w.Flush()
+}
+
+// value - JSON of one value. Numbers stay numbers, decimals are written exactly,
+// everything else is a string formatted like in CSV.
+func (w *JSONLWriter) value(col Column, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return []byte("null"), nil
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), nil
+	case bool:
+		return []byte(strconv.FormatBool(v)), nil
+	case float64:
+		return json.Marshal(v)
+	case utils.Decimal:
+		if v.Kind == utils.DecimalFinite {
+			return []byte(v.String()), nil
+		}
+	}
+	return json.Marshal(FormatValue(col, v))
+}
@@ -0,0 +1,172 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+	// parquet
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	// ParquetMaxPrecision - Widest DECIMAL written as DECIMAL logical type, wider ones are written as text
+	ParquetMaxPrecision = 38
+)
+
+// ParquetWriter - Write extract rows as Apache Parquet with typed columns.
+// DECIMAL(p,s) becomes DECIMAL logical type, DATE becomes DATE, TIME and
+// TIMESTAMP become TIME_MILLIS and TIMESTAMP_MICROS. Every column is optional.
+type ParquetWriter struct {
+	w    io.Writer
+	pw   *writer.CSVWriter
+	cols []Column
+	// decimals - Byte length of FIXED_LEN_BYTE_ARRAY per column, 0 if not a typed decimal
+	decimals []int
+}
+
+// NewParquetWriter - ParquetWriter on w, the schema is created by WriteHeader
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{w: w}
+}
+
+// WriteHeader - Create parquet schema from the result columns
+func (w *ParquetWriter) WriteHeader(cols []Column) error {
+	w.cols = cols
+	w.decimals = make([]int, len(cols))
+	md := make([]string, len(cols))
+	for i, col := range cols {
+		if col.Name == "" || strings.ContainsAny(col.Name, ",=") {
+			return fmt.Errorf("parquet: column name %q not supported, use an alias", col.Name)
+		}
+		// inname is only the internal field name, the file keeps col.Name
+		tag := "name=" + col.Name + ", inname=C" + strconv.Itoa(i) + ", repetitiontype=OPTIONAL, "
+		switch col.Kind {
+		case KindInt:
+			tag += "type=INT64"
+		case KindFloat:
+			tag += "type=DOUBLE"
+		case KindDecimal:
+			if !parquetDecimal(col) {
+				tag += "type=BYTE_ARRAY, convertedtype=UTF8"
+				break
+			}
+			w.decimals[i] = decimalLength(col.Precision)
+			tag += fmt.Sprintf("type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, precision=%d, scale=%d, length=%d",
+				col.Precision, col.Scale, w.decimals[i])
+		case KindBool:
+			tag += "type=BOOLEAN"
+		case KindDate:
+			tag += "type=INT32, convertedtype=DATE"
+		case KindTime:
+			tag += "type=INT32, convertedtype=TIME_MILLIS"
+		case KindTimestamp:
+			tag += "type=INT64, convertedtype=TIMESTAMP_MICROS"
+		case KindBinary:
+			tag += "type=BYTE_ARRAY"
+		default:
+			tag += "type=BYTE_ARRAY, convertedtype=UTF8"
+		}
+		md[i] = tag
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(md, w.w, 1)
+	if err != nil {
+		return err
+	}
+	w.pw = pw
+	return nil
+}
+
+// Write - Convert one row into parquet values, nil is written as NULL
+func (w *ParquetWriter) Write(values []interface{}) error {
+	rec := make([]interface{}, len(values))
+	for i, v := range values {
+		value, err := w.value(i, v)
+		if err != nil {
+			return fmt.Errorf("parquet: column %s: %v", w.cols[i].Name, err)
+		}
+		rec[i] = value
+	}
+	return w.pw.Write(rec)
+}
+
+// Close - Write remaining row group and the file footer
+func (w *ParquetWriter) Close() error {
+	if w.pw == nil {
+		return nil
+	}
+	return w.pw.WriteStop()
+}
+
+// value - Parquet value of column i
+func (w *ParquetWriter) value(i int, v interface{}) (interface{}, error) {
+	col := w.cols[i]
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case int64, float64, bool:
+		return v, nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case utils.Decimal:
+		if w.decimals[i] == 0 {
+			return v.String(), nil
+		}
+		if v.Kind != utils.DecimalFinite {
+			return nil, fmt.Errorf("%s can not be stored as DECIMAL", v.String())
+		}
+		return decimalBytes(v.Round(col.Scale), w.decimals[i])
+	case time.Time:
+		switch col.Kind {
+		case KindDate:
+			days := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+			return int32(days), nil
+		case KindTime:
+			ms := ((v.Hour()*60+v.Minute())*60+v.Second())*1000 + v.Nanosecond()/int(time.Millisecond)
+			return int32(ms), nil
+		}
+		return v.Unix()*1000000 + int64(v.Nanosecond()/int(time.Microsecond)), nil
+	}
+	return FormatValue(col, v), nil
+}
+
+// parquetDecimal - True if col has a fixed precision and scale, floating DECIMAL reports scale 32767
+func parquetDecimal(col Column) bool {
+	return col.Precision > 0 && col.Precision <= ParquetMaxPrecision && col.Scale >= 0 && col.Scale <= col.Precision
+}
+
+// decimalLength - Bytes needed to store precision digits as two's complement
+func decimalLength(precision int) int {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	n := 1
+	for new(big.Int).Lsh(big.NewInt(1), uint(8*n-1)).Cmp(max) < 0 {
+		n++
+	}
+	return n
+}
+
+// decimalBytes - Unscaled value of d as big endian two's complement of n bytes
+func decimalBytes(d utils.Decimal, n int) (string, error) {
+	m := new(big.Int)
+	if d.Mantissa != nil {
+		m.Set(d.Mantissa)
+	}
+	if m.BitLen() > 8*n-1 {
+		return "", fmt.Errorf("%s exceeds precision", d.String())
+	}
+	if d.Neg && m.Sign() != 0 {
+		m.Sub(new(big.Int).Lsh(big.NewInt(1), uint(8*n)), m)
+	}
+	b := m.Bytes()
+	buf := make([]byte, n)
+	copy(buf[n-len(b):], b)
+	return string(buf), nil
+}
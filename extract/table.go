@@ -0,0 +1,160 @@
+package extract
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+
+	"github.com/go-ini/ini"
+)
+
+// Table - Definition of a single table extract
+type Table struct {
+	// Name - Display name of the extract, ie EKKO
+	Name string
+	// SQL - Query to execute, parameters are marked with ?
+	SQL string
+	// Params - Parameter names in the order they are bound to the query
+	Params []string
+	// Output - Output filename without extension
+	Output string
+	// SingleLine - Replace CR/LF inside text columns with a space
+	SingleLine bool
+	// Scale - Digits after decimal point for DECIMAL, 0 uses config.ini [save] scale
+	Scale int
+	// Format - Output format, see FormatCSV etc. Empty uses config.ini [save] format
+	Format string
+	// Chunk - Split the date range into ChunkDay, ChunkMonth or ChunkPeriod parts, empty for one query
+	Chunk string
+	// ChunkArg - Position of the range start in the query arguments, the end follows it
+	ChunkArg int
+	// Profile - Connection profile [server.<name>] of config.ini, empty for utils.ProfileEnv or [server]
+	Profile string
+	// Timeout - Limit of the query, 0 uses the query_timeout of the profile
+	Timeout time.Duration
+	// Client - SAP client bound to $$client$$, empty for config.ini [server] client
+	Client string
+	// Schema - SAP schema replacing $$schema$$, empty for config.ini [server] schema
+	Schema string
+	// ConsolSchema - Consolidation schema replacing $$consol$$, empty for config.ini [server] consol_schema
+	ConsolSchema string
+	// Region - Company group of config.ini [region] bound to $$coy$$, empty for [region] default
+	Region string
+	// Bukrs - Company codes bound to $$coy$$ instead of Region
+	Bukrs []string
+	// Delta - Changed rows query for RunDelta, nil if the table has no delta mode
+	Delta *Delta
+}
+
+// LoadTable - Read table definition from ini file
+func LoadTable(p string) (Table, error) {
+	var t Table
+
+	iniCfg, err := ini.Load(p)
+	if err != nil {
+		return t, err
+	}
+	iniSection := iniCfg.Section("")
+	t.Name = iniSection.Key("name").String()
+	t.SQL = iniSection.Key("sql").String()
+	t.Params = iniSection.Key("params").Strings(",")
+	t.Output = iniSection.Key("output").String()
+	t.SingleLine = iniSection.Key("single_line").MustBool(false)
+	t.Scale = iniSection.Key("scale").MustInt(0)
+	t.Format = iniSection.Key("format").String()
+	t.Chunk = iniSection.Key("chunk").String()
+
+	if deltaSQL := iniSection.Key("delta_sql").String(); deltaSQL != "" {
+		t.Delta = &Delta{
+			SQL:     deltaSQL,
+			Params:  iniSection.Key("delta_params").Strings(","),
+			Columns: iniSection.Key("delta_columns").Strings(","),
+			Created: iniSection.Key("delta_created").String(),
+		}
+		if len(t.Delta.Columns) == 0 {
+			return t, fmt.Errorf("%s: delta_sql without delta_columns", p)
+		}
+	}
+
+	if t.SQL == "" {
+		return t, fmt.Errorf("%s: no sql defined", p)
+	}
+	if t.Name == "" {
+		t.Name = strings.ToUpper(strings.TrimSuffix(filepath.Base(p), filepath.Ext(p)))
+	}
+	if t.Output == "" {
+		t.Output = strings.ToLower(t.Name)
+	}
+	if chunkParam := iniSection.Key("chunk_param").String(); chunkParam != "" {
+		t.ChunkArg = -1
+		for i, name := range t.Params {
+			if name == chunkParam {
+				t.ChunkArg = i
+			}
+		}
+		if t.ChunkArg < 0 {
+			return t, fmt.Errorf("%s: chunk_param %q is not in params", p, chunkParam)
+		}
+	}
+
+	return t, nil
+}
+
+// QueryTable - Extract of the SQL file p, named after the file. Its {{name}} parameters are
+// bound with BindNamed.
+func QueryTable(p string) (Table, error) {
+	var t Table
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return t, err
+	}
+	t.SQL = string(b)
+	if strings.TrimSpace(t.SQL) == "" {
+		return t, fmt.Errorf("%s: no sql defined", p)
+	}
+	t.Name = strings.ToUpper(strings.TrimSuffix(filepath.Base(p), filepath.Ext(p)))
+	t.Output = strings.ToLower(t.Name)
+	return t, nil
+}
+
+// BindNamed - Bind the {{name}} parameters of the query of t to values, see utils.BindNamed.
+// Returns the arguments of the query and the text of every value used.
+func (t *Table) BindNamed(values map[string]string) ([]interface{}, map[string]string, error) {
+	query, args, used, err := utils.BindNamed(t.SQL, values)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", t.Name, err)
+	}
+	t.SQL = query
+	return args, used, nil
+}
+
+// usesCompanies - True if the query or the delta query has a company list
+func (t Table) usesCompanies() bool {
+	if t.Delta != nil && strings.Contains(t.Delta.SQL, utils.CompanyPlaceholder) {
+		return true
+	}
+	return strings.Contains(t.SQL, utils.CompanyPlaceholder)
+}
+
+// bind - Query of t with client and company codes bound in front of, between or after args
+func (t Table) bind(args []interface{}) (string, []interface{}, error) {
+	return utils.BindParams(t.SQL, args, t.Client, t.Bukrs)
+}
+
+// Args - Arrange named values into query arguments following t.Params
+func (t Table) Args(values map[string]string) ([]interface{}, error) {
+	var args []interface{}
+	for _, name := range t.Params {
+		v, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing parameter %q", t.Name, name)
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
@@ -0,0 +1,95 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+// output formats, [save] format or --format
+const (
+	// FormatCSV - Delimiter separated text, semicolon by default
+	FormatCSV = "csv"
+	// FormatTSV - Tab separated text
+	FormatTSV = "tsv"
+	// FormatJSONL - One JSON object per row
+	FormatJSONL = "jsonl"
+	// FormatParquet - Apache Parquet with typed columns
+	FormatParquet = "parquet"
+)
+
+// Writer - Output format of an extract
+type Writer interface {
+	// WriteHeader - Called once with the result columns before any row
+	WriteHeader(cols []Column) error
+	// Write - Write one row, values as returned by row.values
+	Write(values []interface{}) error
+	// Close - Flush buffered rows, the underlying io.Writer is left open
+	Close() error
+}
+
+// ParseFormat - Normalize a format name, empty gives FormatCSV
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatTSV:
+		return FormatTSV, nil
+	case FormatJSONL, "jsonlines", "ndjson":
+		return FormatJSONL, nil
+	case FormatParquet:
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected csv, tsv, jsonl or parquet", s)
+}
+
+// ParseDelimiter - Delimiter from config, a single character or one of
+// semicolon, comma, tab and pipe. A bare ; is a comment in ini files.
+func ParseDelimiter(s string) (rune, error) {
+	switch strings.ToLower(s) {
+	case "", "semicolon":
+		return ';', nil
+	case "comma":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	case "pipe":
+		return '|', nil
+	}
+	r := []rune(s)
+	if len(r) != 1 || r[0] == '"' || r[0] == '\r' || r[0] == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q", s)
+	}
+	return r[0], nil
+}
+
+// NewWriter - Writer of format on w, CSV options and NULL text are taken from cfg
+func NewWriter(w io.Writer, format string, cfg utils.Config) (Writer, error) {
+	switch format {
+	case FormatCSV, FormatTSV:
+		cw := NewCSVWriter(w)
+		cw.Null = cfg.Null
+		cw.UseCRLF = cfg.CRLF
+		quote, err := ParseQuote(cfg.Quote)
+		if err != nil {
+			return nil, err
+		}
+		cw.Quote = quote
+		if format == FormatTSV {
+			cw.Comma = '\t'
+			return cw, nil
+		}
+		if cw.Comma, err = ParseDelimiter(cfg.Delimiter); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	case FormatParquet:
+		return NewParquetWriter(w), nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
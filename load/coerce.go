@@ -0,0 +1,93 @@
+package load
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+
+	hdb "github.com/SAP/go-hdb/driver"
+)
+
+// accepted input layouts, tried in order
+var (
+	dateLayouts      = []string{"20060102", "2006-01-02", "02.01.2006"}
+	timeLayouts      = []string{"150405", "15:04:05"}
+	timestampLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05", "20060102150405", "2006-01-02"}
+)
+
+// Coerce - Value of text s for a parameter of col. Empty text is an empty string for text
+// columns, NULL for other nullable columns and an error for the rest. A NULL text column
+// needs the NULL text of Job.Null.
+func Coerce(col utils.Column, s string) (interface{}, error) {
+	kind := extract.ColumnKind(col.Type)
+	if kind != extract.KindString {
+		s = strings.TrimSpace(s)
+	}
+	if s == "" {
+		switch {
+		case kind == extract.KindString:
+			return "", nil
+		case col.Nullable:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s %s can not be empty", col.Name, col.Type)
+	}
+
+	switch kind {
+	case extract.KindInt:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not an integer", col.Name, s)
+		}
+		return v, nil
+	case extract.KindFloat:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a number", col.Name, s)
+		}
+		return v, nil
+	case extract.KindDecimal:
+		r, ok := utils.ParseDecimalText(s)
+		if !ok {
+			return nil, fmt.Errorf("%s: %q is not a decimal", col.Name, s)
+		}
+		return (*hdb.Decimal)(r), nil
+	case extract.KindBool:
+		switch strings.ToUpper(s) {
+		case "TRUE", "1", "X", "Y":
+			return true, nil
+		case "FALSE", "0", "N":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s: %q is not a boolean", col.Name, s)
+	case extract.KindDate:
+		return parseTime(col, s, dateLayouts)
+	case extract.KindTime:
+		return parseTime(col, s, timeLayouts)
+	case extract.KindTimestamp:
+		return parseTime(col, s, timestampLayouts)
+	case extract.KindBinary:
+		v, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not hex encoded", col.Name, s)
+		}
+		return v, nil
+	}
+	return s, nil
+}
+
+// parseTime - s in the first matching of layouts, UTC
+func parseTime(col utils.Column, s string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s: %q is not a %s, expected %s", col.Name, s, col.Type, layouts[0])
+}
@@ -0,0 +1,87 @@
+package load
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+
+	hdb "github.com/SAP/go-hdb/driver"
+)
+
+func TestCoerce(t *testing.T) {
+	text := utils.Column{Name: "TEXT", Type: "NVARCHAR"}
+	nullText := utils.Column{Name: "TEXT", Type: "NVARCHAR", Nullable: true}
+	dec := utils.Column{Name: "AMOUNT", Type: "DECIMAL"}
+	nullDec := utils.Column{Name: "AMOUNT", Type: "DECIMAL", Nullable: true}
+
+	tests := []struct {
+		col  utils.Column
+		in   string
+		want interface{}
+	}{
+		{text, "", ""},
+		{nullText, "", ""},
+		{text, " a b ", " a b "},
+		{nullDec, "", nil},
+		{nullDec, " ", nil},
+		{dec, "-12.30", "-123/10"},
+		{dec, "+7", "7"},
+		{dec, " 1.5 ", "3/2"},
+		{utils.Column{Name: "N", Type: "INTEGER"}, " -42 ", int64(-42)},
+		{utils.Column{Name: "F", Type: "DOUBLE"}, "1.5e3", float64(1500)},
+		{utils.Column{Name: "B", Type: "BOOLEAN"}, "x", true},
+		{utils.Column{Name: "B", Type: "BOOLEAN"}, "N", false},
+		{utils.Column{Name: "D", Type: "DATE"}, "20190131", time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{utils.Column{Name: "D", Type: "DATE"}, "2019-01-31", time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{utils.Column{Name: "D", Type: "DATE"}, "31.01.2019", time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{utils.Column{Name: "T", Type: "TIMESTAMP"}, "2019-01-31 10:20:30.5", time.Date(2019, 1, 31, 10, 20, 30, 500000000, time.UTC)},
+		{utils.Column{Name: "X", Type: "VARBINARY"}, "00ff", []byte{0x00, 0xff}},
+	}
+	for _, tt := range tests {
+		v, err := Coerce(tt.col, tt.in)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.col.Type, tt.in, err)
+			continue
+		}
+		if d, ok := v.(*hdb.Decimal); ok {
+			v = (*big.Rat)(d).RatString()
+		}
+		if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("%s %q = %#v, want %#v", tt.col.Type, tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestCoerceInvalid(t *testing.T) {
+	dec := utils.Column{Name: "AMOUNT", Type: "DECIMAL", Nullable: true}
+	tests := []struct {
+		col utils.Column
+		in  string
+	}{
+		// big.Rat takes these, a file does not
+		{dec, "1/3"},
+		{dec, "1e3"},
+		{dec, "1E3"},
+		{dec, "0x10"},
+		{dec, "0b11"},
+		{dec, "0o17"},
+		{dec, "1_000"},
+		{dec, "1,5"},
+		{dec, ".5"},
+		{dec, "5."},
+		{utils.Column{Name: "AMOUNT", Type: "DECIMAL"}, ""},
+		{utils.Column{Name: "N", Type: "INTEGER"}, "1.0"},
+		{utils.Column{Name: "B", Type: "BOOLEAN"}, "maybe"},
+		{utils.Column{Name: "D", Type: "DATE"}, "2019-02-30"},
+		{utils.Column{Name: "X", Type: "VARBINARY"}, "0g"},
+	}
+	for _, tt := range tests {
+		if v, err := Coerce(tt.col, tt.in); err == nil {
+			t.Errorf("%s %q = %v, want an error", tt.col.Type, tt.in, v)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package load
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+
+	hdb "github.com/SAP/go-hdb/driver"
+)
+
+// maxDecimalDigits - Digits after decimal point of HANA DECIMAL at most
+const maxDecimalDigits = 34
+
+// DiffJob - Comparison of a CSV file with the rows of a HANA table
+type DiffJob struct {
+	// Table - Table SCHEMA.TABLE, $$schema$$ and $$consol$$ are replaced from config
+	Table string
+	// File - CSV file to compare
+	File string
+	// Map - Column mapping COLUMN=SOURCE, see Job
+	Map []string
+	// NoHeader - The file has no header line, columns are mapped by position
+	NoHeader bool
+	// Delimiter - CSV delimiter, see extract.ParseDelimiter. Empty uses config.ini [save] delimiter
+	Delimiter string
+	// Keys - Columns identifying a row, empty for the primary key
+	Keys []string
+	// Profile - Connection profile [server.<name>] of config.ini, empty for utils.ProfileEnv or [server]
+	Profile string
+	// SQL - File for the statements turning the table into the file, empty for none
+	SQL string
+	// Upload - File for the added and changed lines of the file, to load with ModeUpsert, empty for none
+	Upload string
+	// Null - Text read as NULL, see Job. Without it the file can not tell NULL from empty text,
+	// so empty text equals NULL in nullable text columns, as written by dl_gl_consol_pack_map.
+	Null string
+}
+
+// change - Field of a row that differs between table and file
+type change struct {
+	col         string
+	table, file string
+}
+
+// tableRows - extract.Writer keeping the rows of a table in memory
+type tableRows struct {
+	cols []extract.Column
+	rows [][]interface{}
+}
+
+func (t *tableRows) WriteHeader(cols []extract.Column) error {
+	t.cols = cols
+	return nil
+}
+
+func (t *tableRows) Write(values []interface{}) error {
+	t.rows = append(t.rows, values)
+	return nil
+}
+
+func (t *tableRows) Close() error {
+	return nil
+}
+
+// Diff - Read config p and compare j.File with j.Table by key. Added, removed and changed
+// rows are printed, changed rows field by field. Returns the number of differing rows.
+func Diff(p string, j DiffJob) (int, error) {
+	// read config file
+	utils.WriteMsg("READ CONFIG")
+	cfg, err := utils.LoadProfile(p, j.Profile)
+	if err != nil {
+		return 0, err
+	}
+	if cfg.Profile != "" {
+		utils.WriteMsg("PROFILE: " + cfg.Profile)
+	}
+	schema, table, err := utils.SplitTableName(cfg.Query(j.Table))
+	if err != nil {
+		return 0, err
+	}
+	if j.Delimiter == "" {
+		j.Delimiter = cfg.Delimiter
+	}
+
+	utils.WriteMsg("READ FILE: " + j.File)
+	header, lines, err := readFile(j.File, j.Delimiter, !j.NoHeader)
+	if err != nil {
+		return 0, err
+	}
+
+	utils.WriteMsg("OPEN HDB")
+	db, err := cfg.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	utils.WriteMsg("READ CATALOG: " + schema + "." + table)
+	cols, err := utils.TableColumns(db, schema, table)
+	if err != nil {
+		return 0, err
+	}
+	width, fields, err := mapFile(cols, header, lines, j.Map)
+	if err != nil {
+		return 0, err
+	}
+	keys, err := tableKeys(db, schema, table, fields, j.Keys)
+	if err != nil {
+		return 0, err
+	}
+
+	utils.WriteMsg("VALIDATE: " + strconv.Itoa(len(lines)) + " rows")
+	first := 1
+	if header != nil {
+		first = 2
+	}
+	records, rejects := validate(fields, width, lines, header != nil, j.Null)
+	records, dups := uniqueKeys(records, keys, lines, first)
+	rejects = append(rejects, dups...)
+	sortRejects(rejects)
+	if err := saveRejects(rejectPath(j.File, ""), j.Delimiter, header, rejects); err != nil {
+		return 0, err
+	}
+	if len(rejects) != 0 {
+		return 0, fmt.Errorf("%d of %d rows rejected, nothing compared", len(rejects), len(lines))
+	}
+
+	target := utils.QuoteIdentifier(schema) + "." + utils.QuoteIdentifier(table)
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = utils.QuoteIdentifier(f.col.Name)
+	}
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		order[i] = names[k]
+	}
+	var live tableRows
+	t := extract.Table{
+		Name:    table,
+		SQL:     "select " + strings.Join(names, ", ") + " from " + target + " order by " + strings.Join(order, ", "),
+		Format:  "rows",
+		Timeout: cfg.QueryTimeout,
+	}
+	if _, err := extract.Extract(db, t, &live); err != nil {
+		return 0, err
+	}
+
+	// rows of the table by key
+	byKey := make(map[string]int, len(live.rows))
+	for i, values := range live.rows {
+		byKey[keyString(record{values: values}, keys)] = i
+	}
+
+	var stmts []string
+	var upload [][]string
+	added, changed, unchanged := 0, 0, 0
+	seen := make(map[int]bool, len(records))
+	for _, rec := range records {
+		i, ok := byKey[keyString(rec, keys)]
+		if !ok {
+			added++
+			fmt.Printf("ADDED    line %d: %s\n", rec.line, describeKey(fields, keys, rec.values))
+			stmts = append(stmts, insertLiteral(target, fields, rec.values))
+			upload = append(upload, lines[rec.line-first])
+			continue
+		}
+		seen[i] = true
+		changes, sets := compare(fields, live.rows[i], rec.values, j.Null == "")
+		if len(changes) == 0 {
+			unchanged++
+			continue
+		}
+		changed++
+		fmt.Printf("CHANGED  line %d: %s\n", rec.line, describeKey(fields, keys, rec.values))
+		for _, c := range changes {
+			fmt.Printf("    %s: %s -> %s\n", c.col, c.table, c.file)
+		}
+		stmts = append(stmts, "update "+target+" set "+strings.Join(sets, ", ")+" where "+whereLiteral(fields, keys, rec.values)+";")
+		upload = append(upload, lines[rec.line-first])
+	}
+	removed := 0
+	for i, values := range live.rows {
+		if seen[i] {
+			continue
+		}
+		removed++
+		fmt.Printf("REMOVED  %s\n", describeKey(fields, keys, values))
+		stmts = append(stmts, "delete from "+target+" where "+whereLiteral(fields, keys, values)+";")
+	}
+	utils.WriteMsg(fmt.Sprintf("DIFF: %d added, %d removed, %d changed, %d unchanged", added, removed, changed, unchanged))
+
+	if j.SQL != "" {
+		utils.WriteMsg("WRITE SQL: " + j.SQL)
+		if err := writeLines(j.SQL, stmts); err != nil {
+			return 0, err
+		}
+	}
+	if j.Upload != "" {
+		utils.WriteMsg("WRITE UPLOAD: " + j.Upload)
+		if err := writeUpload(j.Upload, j.Delimiter, header, upload); err != nil {
+			return 0, err
+		}
+		if removed != 0 {
+			utils.WriteMsg("UPLOAD: " + strconv.Itoa(removed) + " removed rows are not in the upload file, delete them with the SQL file")
+		}
+	}
+	return added + removed + changed, nil
+}
+
+// compare - Fields of file differing from row of the table, and their SQL assignments.
+// With emptyNull empty text of the file equals NULL of a nullable text column.
+func compare(fields []field, row, file []interface{}, emptyNull bool) ([]change, []string) {
+	var changes []change
+	var sets []string
+	for i, f := range fields {
+		if canonical(row[i]) == canonical(file[i]) {
+			continue
+		}
+		if emptyNull && f.col.Nullable && row[i] == nil && file[i] == "" {
+			continue
+		}
+		changes = append(changes, change{col: f.col.Name, table: display(f.col, row[i]), file: display(f.col, file[i])})
+		sets = append(sets, utils.QuoteIdentifier(f.col.Name)+" = "+literal(f.col, file[i]))
+	}
+	return changes, sets
+}
+
+// canonical - Comparable text of a value from the table or from the file.
+// Both sides may give the same value in different types, ie utils.Decimal and *hdb.Decimal.
+func canonical(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "\x00NULL"
+	case *hdb.Decimal:
+		return (*big.Rat)(v).RatString()
+	case utils.Decimal:
+		if r := v.Rat(); r != nil {
+			return r.RatString()
+		}
+		return v.String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return hex.EncodeToString(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// display - Value v of col for the report
+func display(col utils.Column, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return strconv.Quote(v)
+	case *hdb.Decimal:
+		return ratText((*big.Rat)(v))
+	}
+	return extract.FormatValue(extract.Column{Kind: extract.ColumnKind(col.Type)}, v)
+}
+
+// ratText - Shortest exact decimal notation of r, r parsed from decimal text has one
+func ratText(r *big.Rat) string {
+	for n := 0; n < maxDecimalDigits; n++ {
+		s := r.FloatString(n)
+		if v, ok := new(big.Rat).SetString(s); ok && v.Cmp(r) == 0 {
+			return s
+		}
+	}
+	return r.FloatString(maxDecimalDigits)
+}
+
+// literal - SQL literal of value v of col
+func literal(col utils.Column, v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	switch extract.ColumnKind(col.Type) {
+	case extract.KindInt, extract.KindFloat, extract.KindDecimal, extract.KindBool:
+		return display(col, v)
+	case extract.KindBinary:
+		return "X'" + canonical(v) + "'"
+	case extract.KindDate:
+		return "DATE'" + display(col, v) + "'"
+	case extract.KindTime:
+		return "TIME'" + display(col, v) + "'"
+	case extract.KindTimestamp:
+		return "TIMESTAMP'" + v.(time.Time).Format("2006-01-02 15:04:05.999999999") + "'"
+	}
+	return "'" + strings.Replace(fmt.Sprint(v), "'", "''", -1) + "'"
+}
+
+// describeKey - Key columns and values of a row for the report
+func describeKey(fields []field, keys []int, values []interface{}) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fields[k].col.Name + "=" + display(fields[k].col, values[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// whereLiteral - Condition on the key values of a row as SQL literals
+func whereLiteral(fields []field, keys []int, values []interface{}) string {
+	conds := make([]string, len(keys))
+	for i, k := range keys {
+		conds[i] = utils.QuoteIdentifier(fields[k].col.Name) + " = " + literal(fields[k].col, values[k])
+	}
+	return strings.Join(conds, " and ")
+}
+
+// insertLiteral - Insert statement of a row as SQL literals
+func insertLiteral(target string, fields []field, values []interface{}) string {
+	names := make([]string, len(fields))
+	lits := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = utils.QuoteIdentifier(f.col.Name)
+		lits[i] = literal(f.col, values[i])
+	}
+	return "insert into " + target + " (" + strings.Join(names, ", ") + ") values (" + strings.Join(lits, ", ") + ");"
+}
+
+// writeLines - Write lines to file p
+func writeLines(p string, lines []string) error {
+	file, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(file, l); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// writeUpload - Write header, if any, and lines as CSV file p
+func writeUpload(p, delimiter string, header []string, lines [][]string) error {
+	comma, err := extract.ParseDelimiter(delimiter)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	w.Comma = comma
+	if header != nil {
+		w.Write(header)
+	}
+	w.WriteAll(lines)
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
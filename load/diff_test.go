@@ -0,0 +1,75 @@
+package load
+
+import (
+	"reflect"
+	"testing"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+func TestCompareEmptyText(t *testing.T) {
+	fields := []field{
+		{col: utils.Column{Name: "KEY", Type: "NVARCHAR", Length: 10}, src: 0},
+		{col: utils.Column{Name: "NULLABLE", Type: "NVARCHAR", Length: 10, Nullable: true}, src: 1},
+		{col: utils.Column{Name: "REQUIRED", Type: "NVARCHAR", Length: 10, Default: true}, src: 2},
+	}
+	tests := []struct {
+		name    string
+		null    string
+		row     []interface{}
+		line    []string
+		changed []string
+	}{
+		{
+			name: "empty text equals NULL without NULL text",
+			row:  []interface{}{"A", nil, "x"},
+			line: []string{"A", "", "x"},
+		},
+		{
+			name:    "empty text of a column without NULL differs",
+			row:     []interface{}{"A", "y", ""},
+			line:    []string{"A", "", "x"},
+			changed: []string{"NULLABLE", "REQUIRED"},
+		},
+		{
+			name:    "value replaces NULL",
+			row:     []interface{}{"A", nil, "x"},
+			line:    []string{"A", "y", "x"},
+			changed: []string{"NULLABLE"},
+		},
+		{
+			name:    "empty text differs from NULL with NULL text",
+			null:    `\N`,
+			row:     []interface{}{"A", nil, "x"},
+			line:    []string{"A", "", "x"},
+			changed: []string{"NULLABLE"},
+		},
+		{
+			name: "NULL text equals NULL",
+			null: `\N`,
+			row:  []interface{}{"A", nil, "x"},
+			line: []string{"A", `\N`, "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the file values as Diff reads them
+			records, rejects := validate(fields, len(fields), [][]string{tt.line}, false, tt.null)
+			if len(rejects) != 0 {
+				t.Fatalf("rejected: %v", rejects[0].reasons)
+			}
+			changes, sets := compare(fields, tt.row, records[0].values, tt.null == "")
+			var changed []string
+			for _, c := range changes {
+				changed = append(changed, c.col)
+			}
+			if !reflect.DeepEqual(changed, tt.changed) {
+				t.Errorf("changed %v, want %v", changed, tt.changed)
+			}
+			if len(sets) != len(changes) {
+				t.Errorf("%d assignments for %d changes", len(sets), len(changes))
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package load
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+)
+
+const (
+	// DefaultBatchSize - Rows per bulk insert round trip when config has none
+	DefaultBatchSize = 1000
+)
+
+// Job - Load of one CSV file into a HANA table
+type Job struct {
+	// Table - Target table SCHEMA.TABLE, $$schema$$ and $$consol$$ are replaced from config
+	Table string
+	// File - CSV file to load
+	File string
+	// Map - Column mapping COLUMN=SOURCE, SOURCE is a header name or a position starting at 1
+	Map []string
+	// NoHeader - The file has no header line, columns are mapped by position
+	NoHeader bool
+	// Delimiter - CSV delimiter, see extract.ParseDelimiter. Empty uses config.ini [save] delimiter
+	Delimiter string
+	// BatchSize - Rows per bulk insert round trip, 0 uses config.ini [load] batch_size
+	BatchSize int
+	// Target - Connection profile written to, see utils.LoadTarget
+	Target string
+	// Yes - Do not ask before writing
+	Yes bool
+	// DryRun - Validate the file against the table without writing
+	DryRun bool
+	// Reject - File the rejected lines are written to, empty uses File with extension .rej
+	Reject string
+	// Mode - How existing rows are treated, one of Modes, empty is ModeAppend
+	Mode string
+	// Keys - Key columns of ModeReplace and ModeUpsert, ModeUpsert defaults to the primary key
+	Keys []string
+	// Null - Text read as NULL, ie \N. Without it no text column gets NULL, see Coerce
+	Null string
+	// Snapshot - Archive the rows of the table before writing, see TakeSnapshot
+	Snapshot bool
+	// Rows - Values to load instead of the lines of File, which then only names the source.
+	// They are in the order of the positions of Map, nil is NULL, see rowLines.
+	Rows [][]interface{}
+}
+
+// record - Coerced values of one CSV line
+type record struct {
+	line   int
+	values []interface{}
+}
+
+// Run - Read config p and load j.File into j.Table
+func Run(p string, j Job) error {
+	// read config file
+	utils.WriteMsg("READ CONFIG")
+	cfg, err := utils.LoadTarget(p, j.Target)
+	if err != nil {
+		return err
+	}
+	schema, table, err := utils.SplitTableName(cfg.Query(j.Table))
+	if err != nil {
+		return err
+	}
+	if j.Delimiter == "" {
+		j.Delimiter = cfg.Delimiter
+	}
+	if j.BatchSize == 0 {
+		j.BatchSize = cfg.BatchSize
+	}
+	if j.BatchSize <= 0 {
+		j.BatchSize = DefaultBatchSize
+	}
+	if j.Mode, err = ParseMode(j.Mode); err != nil {
+		return err
+	}
+
+	var header []string
+	var lines [][]string
+	if j.Rows != nil {
+		if j.Null == "" {
+			j.Null = SnapshotNull
+		}
+		lines = rowLines(j.Rows, j.Null)
+	} else {
+		utils.WriteMsg("READ FILE: " + j.File)
+		if header, lines, err = readFile(j.File, j.Delimiter, !j.NoHeader); err != nil {
+			return err
+		}
+	}
+
+	utils.WriteMsg("OPEN HDB")
+	db, err := cfg.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	utils.WriteMsg("READ CATALOG: " + schema + "." + table)
+	cols, err := utils.TableColumns(db, schema, table)
+	if err != nil {
+		return err
+	}
+	width, fields, err := mapFile(cols, header, lines, j.Map)
+	if err != nil {
+		return err
+	}
+	keys, err := loadKeys(db, schema, table, fields, j)
+	if err != nil {
+		return err
+	}
+
+	utils.WriteMsg("VALIDATE: " + strconv.Itoa(len(lines)) + " rows")
+	records, rejects := validate(fields, width, lines, header != nil, j.Null)
+	if j.Mode == ModeUpsert {
+		first := 1
+		if header != nil {
+			first = 2
+		}
+		var dups []reject
+		records, dups = uniqueKeys(records, keys, lines, first)
+		rejects = append(rejects, dups...)
+		sortRejects(rejects)
+	}
+	if err := saveRejects(rejectPath(j.File, j.Reject), j.Delimiter, header, rejects); err != nil {
+		return err
+	}
+	if j.DryRun {
+		utils.WriteMsg("DRY RUN: " + strconv.Itoa(len(records)) + " rows valid, nothing written")
+	}
+	if len(rejects) != 0 {
+		return fmt.Errorf("%d of %d rows rejected, nothing written", len(rejects), len(lines))
+	}
+	if j.DryRun {
+		return nil
+	}
+
+	target := utils.QuoteIdentifier(schema) + "." + utils.QuoteIdentifier(table)
+	if err := utils.ConfirmWrite(cfg, target, action(j.Mode, fields, keys, len(records)), j.Yes); err != nil {
+		return err
+	}
+
+	if j.Snapshot {
+		snap, err := takeSnapshot(db, cfg, schema, table, j.Mode+" "+j.File)
+		if err != nil {
+			return err
+		}
+		utils.WriteMsg("SNAPSHOT: " + snap.ID + ", undo with: snapshot restore --target " + cfg.Profile + " " + snap.ID)
+	}
+
+	sum, err := write(db, target, j.Mode, fields, keys, len(j.Keys) != 0, records, j.BatchSize)
+	if err != nil {
+		return err
+	}
+	utils.WriteMsg("DONE: " + sum.String())
+	return nil
+}
+
+// mapFile - Fields of cols filled from lines, see mapColumns, and the number of values every line must have
+func mapFile(cols []utils.Column, header []string, lines [][]string, spec []string) (int, []field, error) {
+	width := len(header)
+	switch {
+	case header != nil:
+	case len(spec) == 0:
+		// without header and mapping every column is filled by position
+		width = len(cols)
+	case len(lines) != 0:
+		width = len(lines[0])
+	}
+	fields, err := mapColumns(cols, header, width, spec)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, f := range fields {
+		utils.WriteMsg("MAP: " + f.col.Name + " <- " + sourceName(header, f.src))
+	}
+	return width, fields, checkRequired(cols, fields)
+}
+
+// rejectPath - File for the rejected lines of file, reject if given
+func rejectPath(file, reject string) string {
+	if reject != "" {
+		return reject
+	}
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".rej"
+}
+
+// saveRejects - Write rejects to p, or remove an old p when there are none
+func saveRejects(p, delimiter string, header []string, rejects []reject) error {
+	if len(rejects) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	comma, err := extract.ParseDelimiter(delimiter)
+	if err != nil {
+		return err
+	}
+	if err := writeRejects(p, comma, header, rejects); err != nil {
+		return err
+	}
+	utils.WriteMsg("REJECTED: " + strconv.Itoa(len(rejects)) + " rows, see " + p)
+	return nil
+}
+
+// loadKeys - Positions in fields of the key columns of mode j.Mode, which then need a value in every row
+func loadKeys(db *sql.DB, schema, table string, fields []field, j Job) ([]int, error) {
+	switch j.Mode {
+	case ModeAppend, ModeTruncate:
+		if len(j.Keys) != 0 {
+			return nil, fmt.Errorf("mode %s takes no key columns", j.Mode)
+		}
+		return nil, nil
+	case ModeReplace:
+		if len(j.Keys) == 0 {
+			return nil, fmt.Errorf("mode %s needs key columns, use --key", j.Mode)
+		}
+	}
+	return tableKeys(db, schema, table, fields, j.Keys)
+}
+
+// tableKeys - Positions in fields of the key columns names, the primary key of schema.table
+// without names. Key columns then need a value in every row.
+func tableKeys(db *sql.DB, schema, table string, fields []field, names []string) ([]int, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = utils.PrimaryKey(db, schema, table); err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%s.%s has no primary key, use --key", schema, table)
+		}
+	}
+	keys, err := keyFields(fields, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		fields[k].col.Nullable = false
+		fields[k].col.Default = false
+		utils.WriteMsg("KEY: " + fields[k].col.Name)
+	}
+	return keys, nil
+}
+
+// action - Question of ConfirmWrite for mode
+func action(mode string, fields []field, keys []int, rows int) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = fields[k].col.Name
+	}
+	switch mode {
+	case ModeTruncate:
+		return "Replace all rows with " + strconv.Itoa(rows) + " rows"
+	case ModeReplace:
+		return "Replace rows by " + strings.Join(names, ", ") + " with " + strconv.Itoa(rows) + " rows"
+	case ModeUpsert:
+		return "Upsert " + strconv.Itoa(rows) + " rows by " + strings.Join(names, ", ")
+	}
+	return "Insert " + strconv.Itoa(rows) + " rows"
+}
+
+// write - Apply records to target by mode in one transaction. Nothing is written unless every row is.
+// byKey upserts by the key columns instead of the primary key.
+func write(db *sql.DB, target, mode string, fields []field, keys []int, byKey bool, records []record, batchSize int) (Summary, error) {
+	var sum Summary
+	tx, err := db.Begin()
+	if err != nil {
+		return sum, err
+	}
+	// no-op after Commit
+	defer tx.Rollback()
+
+	switch mode {
+	case ModeTruncate:
+		sum.Deleted, err = deleteAll(tx, target)
+	case ModeReplace:
+		sum.Deleted, err = deleteByKey(tx, target, fields, keys, records)
+	}
+	if err != nil {
+		return Summary{}, fmt.Errorf("%v, rolled back", err)
+	}
+	if sum.Deleted != 0 {
+		utils.WriteMsg("DELETED: " + strconv.FormatInt(sum.Deleted, 10) + " rows")
+	}
+
+	values := func(rec record) []interface{} { return rec.values }
+	if mode == ModeUpsert {
+		if byKey {
+			// the values, then the key values of the where condition
+			values = func(rec record) []interface{} {
+				return append(append([]interface{}{}, rec.values...), keyValues(rec, keys)...)
+			}
+		}
+		sum.Upserted, err = execBulk(tx, upsertSQL(target, fields, keys, byKey), records, values, batchSize, "UPSERTED")
+	} else {
+		sum.Inserted, err = execBulk(tx, insertSQL(target, fields), records, values, batchSize, "INSERTED")
+	}
+	if err != nil {
+		return Summary{}, fmt.Errorf("%v, rolled back", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return Summary{}, err
+	}
+	return sum, nil
+}
+
+// readFile - Header, if withHeader, and data lines of CSV file p
+func readFile(p, delimiter string, withHeader bool) ([]string, [][]string, error) {
+	comma, err := extract.ParseDelimiter(delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	var header []string
+	var lines [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if withHeader && header == nil {
+			// Excel writes a byte order mark in front of UTF-8 files
+			rec[0] = strings.TrimPrefix(rec[0], "\ufeff")
+			header = rec
+			continue
+		}
+		lines = append(lines, rec)
+	}
+	if withHeader && header == nil {
+		return nil, nil, fmt.Errorf("%s is empty", p)
+	}
+	return header, lines, nil
+}
+
+// rowLines - Lines of text of rows, like read from a file: nil is the NULL text null and
+// other values are formatted by extract.FormatValue and escaped, see extract.EscapeNull
+func rowLines(rows [][]interface{}, null string) [][]string {
+	lines := make([][]string, len(rows))
+	for i, row := range rows {
+		lines[i] = make([]string, len(row))
+		for k, v := range row {
+			if v == nil {
+				lines[i][k] = null
+				continue
+			}
+			lines[i][k] = extract.EscapeNull(extract.FormatValue(extract.Column{}, v), null)
+		}
+	}
+	return lines
+}
+
+// sourceName - Header name or position of src for messages
+func sourceName(header []string, src int) string {
+	if header != nil {
+		return header[src] + " (" + strconv.Itoa(src+1) + ")"
+	}
+	return strconv.Itoa(src + 1)
+}
+
+// insertSQL - Bulk insert of fields into target
+func insertSQL(target string, fields []field) string {
+	return "bulk insert into " + target + " (" + columnList(fields) + ") values (" + paramList(len(fields)) + ")"
+}
+
+// columnList - Quoted names of the columns of fields separated by comma
+func columnList(fields []field) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = utils.QuoteIdentifier(f.col.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// paramList - n parameters ? separated by comma
+func paramList(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// execBulk - Execute bulk statement query for records within tx, batchSize rows per round trip.
+// args are the parameters of a record, done the message of every finished round trip.
+func execBulk(tx *sql.Tx, query string, records []record, args func(record) []interface{}, batchSize int, done string) (int64, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var count int64
+	for _, rec := range records {
+		if _, err := stmt.Exec(args(rec)...); err != nil {
+			return 0, fmt.Errorf("line %d: %v", rec.line, err)
+		}
+		count++
+		if count%int64(batchSize) == 0 {
+			// an Exec without arguments sends the buffered rows
+			if _, err := stmt.Exec(); err != nil {
+				return 0, err
+			}
+			utils.WriteMsg(done + ": " + strconv.FormatInt(count, 10) + " rows")
+		}
+	}
+	if count%int64(batchSize) != 0 {
+		if _, err := stmt.Exec(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,109 @@
+package load
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+// field - Target column and the position of its value in a CSV record
+type field struct {
+	col utils.Column
+	src int
+}
+
+// mapColumns - Fields of cols filled from records of width values. spec holds COLUMN=SOURCE
+// entries, SOURCE is a header name or a position starting at 1. Without spec entry a column
+// takes the header of the same name, or without header the value at its own position.
+func mapColumns(cols []utils.Column, header []string, width int, spec []string) ([]field, error) {
+	explicit := make(map[string]int)
+	for _, entry := range spec {
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected COLUMN=SOURCE", entry)
+		}
+		name, source := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if columnIndex(cols, name) < 0 {
+			return nil, fmt.Errorf("mapping %q: table has no column %s", entry, name)
+		}
+		src, err := sourceIndex(header, width, source)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q: %v", entry, err)
+		}
+		explicit[strings.ToUpper(cols[columnIndex(cols, name)].Name)] = src
+	}
+
+	var fields []field
+	for i, col := range cols {
+		src, ok := explicit[strings.ToUpper(col.Name)]
+		switch {
+		case ok:
+		case header != nil:
+			src = headerIndex(header, col.Name)
+		case len(spec) == 0 && i < width:
+			src = i
+		default:
+			src = -1
+		}
+		if src >= 0 {
+			fields = append(fields, field{col: col, src: src})
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no column of the file maps to the table, use --map COLUMN=SOURCE")
+	}
+	return fields, nil
+}
+
+// sourceIndex - Position of source in a record: a number counts from 1, anything else is a header name
+func sourceIndex(header []string, width int, source string) (int, error) {
+	if n, err := strconv.Atoi(source); err == nil {
+		if n < 1 || n > width {
+			return -1, fmt.Errorf("position %d out of 1..%d", n, width)
+		}
+		return n - 1, nil
+	}
+	if header == nil {
+		return -1, fmt.Errorf("file has no header, map %q by position", source)
+	}
+	i := headerIndex(header, source)
+	if i < 0 {
+		return -1, fmt.Errorf("no header %q", source)
+	}
+	return i, nil
+}
+
+// headerIndex - Position of name in header, -1 if missing. Case, blanks and
+// punctuation are ignored, so the column "Group 1" matches a header GROUP_1.
+func headerIndex(header []string, name string) int {
+	key := headerKey(name)
+	for i, h := range header {
+		if headerKey(h) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func headerKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+// columnIndex - Position of column name in cols, -1 if missing
+func columnIndex(cols []utils.Column, name string) int {
+	for i, col := range cols {
+		if strings.EqualFold(col.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
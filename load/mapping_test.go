@@ -0,0 +1,95 @@
+package load
+
+import (
+	"reflect"
+	"testing"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+func TestMapColumns(t *testing.T) {
+	cols := []utils.Column{{Name: "YEAR"}, {Name: "Group 1"}, {Name: "REMARK"}}
+	tests := []struct {
+		name   string
+		header []string
+		width  int
+		spec   []string
+		want   map[string]int
+	}{
+		{
+			name:   "header ignores case and punctuation",
+			header: []string{"remark", "GROUP_1", "Year"},
+			width:  3,
+			want:   map[string]int{"YEAR": 2, "Group 1": 1, "REMARK": 0},
+		},
+		{
+			name:   "header with a missing column",
+			header: []string{"YEAR", "OTHER"},
+			width:  2,
+			want:   map[string]int{"YEAR": 0},
+		},
+		{
+			name:  "position without header",
+			width: 3,
+			want:  map[string]int{"YEAR": 0, "Group 1": 1, "REMARK": 2},
+		},
+		{
+			name:  "position without header leaves the columns beyond the width",
+			width: 2,
+			want:  map[string]int{"YEAR": 0, "Group 1": 1},
+		},
+		{
+			name:   "mapping by header name and position",
+			header: []string{"Jahr", "Bemerkung", "GROUP 1"},
+			width:  3,
+			spec:   []string{"year=Jahr", "REMARK = 2"},
+			want:   map[string]int{"YEAR": 0, "Group 1": 2, "REMARK": 1},
+		},
+		{
+			name:  "mapping without header maps only the given columns",
+			width: 5,
+			spec:  []string{"REMARK=5", "YEAR=1"},
+			want:  map[string]int{"YEAR": 0, "REMARK": 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := mapColumns(cols, tt.header, tt.width, tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]int{}
+			for _, f := range fields {
+				got[f.col.Name] = f.src
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mapped %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapColumnsInvalid(t *testing.T) {
+	cols := []utils.Column{{Name: "YEAR"}, {Name: "REMARK"}}
+	tests := []struct {
+		name   string
+		header []string
+		width  int
+		spec   []string
+	}{
+		{"no separator", nil, 2, []string{"YEAR"}},
+		{"empty source", nil, 2, []string{"YEAR="}},
+		{"unknown column", nil, 2, []string{"MONTH=1"}},
+		{"position beyond the width", nil, 2, []string{"YEAR=3"}},
+		{"position zero", nil, 2, []string{"YEAR=0"}},
+		{"name without header", nil, 2, []string{"YEAR=Jahr"}},
+		{"unknown header", []string{"YEAR"}, 1, []string{"REMARK=Bemerkung"}},
+		{"nothing maps", []string{"A", "B"}, 2, nil},
+	}
+	for _, tt := range tests {
+		if _, err := mapColumns(cols, tt.header, tt.width, tt.spec); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
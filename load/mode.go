@@ -0,0 +1,175 @@
+package load
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+const (
+	// ModeAppend - Insert the rows next to the existing ones
+	ModeAppend = "append"
+	// ModeTruncate - Delete all rows of the table, then insert
+	ModeTruncate = "truncate"
+	// ModeReplace - Delete the rows with the key values of the file, ie the years, then insert
+	ModeReplace = "replace"
+	// ModeUpsert - Update the rows with the key of a file row and insert the others, see upsertSQL
+	ModeUpsert = "upsert"
+)
+
+// Modes - Load modes in order of documentation
+var Modes = []string{ModeAppend, ModeTruncate, ModeReplace, ModeUpsert}
+
+// Summary - Rows changed by a load
+type Summary struct {
+	Inserted int64
+	// Upserted - Rows updated or inserted by ModeUpsert, HANA does not tell which
+	Upserted int64
+	Deleted  int64
+}
+
+// String - Summary as printed after a load
+func (s Summary) String() string {
+	if s.Upserted != 0 {
+		return fmt.Sprintf("%d upserted, %d deleted", s.Upserted, s.Deleted)
+	}
+	return fmt.Sprintf("%d inserted, %d deleted", s.Inserted, s.Deleted)
+}
+
+// ParseMode - Load mode of s, empty is ModeAppend
+func ParseMode(s string) (string, error) {
+	if s == "" {
+		return ModeAppend, nil
+	}
+	for _, m := range Modes {
+		if strings.EqualFold(s, m) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid mode %q, expected one of %s", s, strings.Join(Modes, ", "))
+}
+
+// keyFields - Positions in fields of the key columns, every key must be mapped
+func keyFields(fields []field, keys []string) ([]int, error) {
+	var idx []int
+	for _, k := range keys {
+		i := -1
+		for j, f := range fields {
+			if strings.EqualFold(f.col.Name, k) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("key column %s is not mapped", k)
+		}
+		idx = append(idx, i)
+	}
+	return idx, nil
+}
+
+// keyString - Comparable text of the key values of rec
+func keyString(rec record, keys []int) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = canonical(rec.values[k])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// uniqueKeys - records without the ones repeating the key of an earlier record, which are rejected
+func uniqueKeys(records []record, keys []int, lines [][]string, first int) ([]record, []reject) {
+	seen := make(map[string]int)
+	var unique []record
+	var rejects []reject
+	for _, rec := range records {
+		k := keyString(rec, keys)
+		if line, ok := seen[k]; ok {
+			rejects = append(rejects, reject{
+				line:    rec.line,
+				values:  lines[rec.line-first],
+				reasons: []string{"duplicate key of line " + strconv.Itoa(line)},
+			})
+			continue
+		}
+		seen[k] = rec.line
+		unique = append(unique, rec)
+	}
+	return unique, rejects
+}
+
+// sortRejects - Order rejects by line
+func sortRejects(rejects []reject) {
+	sort.Slice(rejects, func(i, j int) bool { return rejects[i].line < rejects[j].line })
+}
+
+// where - Condition on the key columns of fields
+func where(fields []field, keys []int) string {
+	conds := make([]string, len(keys))
+	for i, k := range keys {
+		conds[i] = utils.QuoteIdentifier(fields[k].col.Name) + " = ?"
+	}
+	return strings.Join(conds, " and ")
+}
+
+// keyValues - Values of the key columns of rec
+func keyValues(rec record, keys []int) []interface{} {
+	args := make([]interface{}, len(keys))
+	for i, k := range keys {
+		args[i] = rec.values[k]
+	}
+	return args
+}
+
+// deleteAll - Delete every row of target. Unlike truncate table a delete is part of the transaction.
+func deleteAll(tx *sql.Tx, target string) (int64, error) {
+	res, err := tx.Exec("delete from " + target)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// deleteByKey - Delete the rows of target with the key values of any of records
+func deleteByKey(tx *sql.Tx, target string, fields []field, keys []int, records []record) (int64, error) {
+	stmt, err := tx.Prepare("delete from " + target + " where " + where(fields, keys))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var deleted int64
+	done := make(map[string]bool)
+	for _, rec := range records {
+		k := keyString(rec, keys)
+		if done[k] {
+			continue
+		}
+		done[k] = true
+		res, err := stmt.Exec(keyValues(rec, keys)...)
+		if err != nil {
+			return deleted, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// upsertSQL - Bulk upsert of fields into target, HANA updates the row with the key of a record
+// or inserts it. Rows are matched by the primary key, or with byKey by the key columns.
+func upsertSQL(target string, fields []field, keys []int, byKey bool) string {
+	query := "bulk upsert " + target + " (" + columnList(fields) + ") values (" + paramList(len(fields)) + ")"
+	if byKey {
+		return query + " where " + where(fields, keys)
+	}
+	return query + " with primary key"
+}
@@ -0,0 +1,279 @@
+package load
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/consolpack"
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// DefaultSnapshotDir - Snapshot archive used when config has none
+	DefaultSnapshotDir = "snapshots"
+	// SnapshotNull - NULL in snapshot files, so it is told apart from empty text on restore
+	SnapshotNull = `\N`
+	// snapshotIndex - History of the archive, one section per snapshot
+	snapshotIndex = "index.ini"
+	// snapshotTime - Layout of the time in snapshot ids and index
+	snapshotTime = "20060102-150405"
+)
+
+// Snapshot - Archived contents of a table
+type Snapshot struct {
+	// ID - Name of the snapshot, TABLE-YYYYMMDD-HHMMSS
+	ID string
+	// Table - Table SCHEMA.TABLE
+	Table string
+	// Profile - Connection profile the table was read from, empty in snapshots taken
+	// before the profile was required
+	Profile string
+	// Host - host:port of Profile at that time, a restore must write to the same
+	Host string
+	// Time - When the snapshot was taken
+	Time time.Time
+	// Rows - Number of rows
+	Rows int
+	// File - CSV file of the rows, relative to the archive
+	File string
+	// Reason - What the snapshot was taken for, ie the upload that followed
+	Reason string
+}
+
+// snapshotRows - Row struct of tables archived through utils.StructSQL instead of select *,
+// so their snapshot holds the columns that are downloaded and uploaded
+var snapshotRows = map[string]interface{}{
+	consolpack.GLConsolPackMapTable: consolpack.GLConsolPackMap{},
+}
+
+// snapshotSQL - Query of the rows of schema.table archived by a snapshot
+func snapshotSQL(cfg utils.Config, schema, table string) (string, error) {
+	from := utils.QuoteIdentifier(schema) + "." + utils.QuoteIdentifier(table)
+	for name, row := range snapshotRows {
+		s, t, err := utils.SplitTableName(cfg.Query(name))
+		if err != nil {
+			return "", err
+		}
+		if s == schema && t == table {
+			return utils.StructSQL(from, row)
+		}
+	}
+	return "select * from " + from, nil
+}
+
+// snapshotDir - Snapshot archive of cfg
+func snapshotDir(cfg utils.Config) string {
+	if cfg.SnapshotDir != "" {
+		return cfg.SnapshotDir
+	}
+	return DefaultSnapshotDir
+}
+
+// loadIndex - History of archive dir, a missing index is empty
+func loadIndex(dir string) (*ini.File, error) {
+	p := filepath.Join(dir, snapshotIndex)
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return ini.Empty(), nil
+	}
+	return ini.Load(p)
+}
+
+// saveIndex - Save history of archive dir
+func saveIndex(dir string, index *ini.File) error {
+	p := filepath.Join(dir, snapshotIndex)
+	// through a temporary file so a crash never loses the history
+	return utils.WriteFileAtomic(p, func(w io.Writer) error {
+		_, err := index.WriteTo(w)
+		return err
+	})
+}
+
+func hasSection(index *ini.File, name string) bool {
+	_, err := index.GetSection(name)
+	return err == nil
+}
+
+// readSnapshots - Snapshots of archive dir, oldest first
+func readSnapshots(dir string) ([]Snapshot, error) {
+	index, err := loadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	var snaps []Snapshot
+	for _, section := range index.Sections() {
+		if section.Name() == ini.DefaultSection {
+			continue
+		}
+		t, _ := time.ParseInLocation("2006-01-02 15:04:05", section.Key("time").String(), time.Local)
+		snaps = append(snaps, Snapshot{
+			ID:      section.Name(),
+			Table:   section.Key("table").String(),
+			Profile: section.Key("profile").String(),
+			Host:    section.Key("host").String(),
+			Time:    t,
+			Rows:    section.Key("rows").MustInt(0),
+			File:    section.Key("file").String(),
+			Reason:  section.Key("reason").String(),
+		})
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	return snaps, nil
+}
+
+// Snapshots - Read config p and list the snapshots of its archive, of table only if not empty
+func Snapshots(p, table string) ([]Snapshot, error) {
+	cfg, err := utils.LoadConfig(p)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := readSnapshots(snapshotDir(cfg))
+	if err != nil || table == "" {
+		return snaps, err
+	}
+	schema, name, err := utils.SplitTableName(cfg.Query(table))
+	if err != nil {
+		return nil, err
+	}
+	var found []Snapshot
+	for _, s := range snaps {
+		if s.Table == schema+"."+name {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+// takeSnapshot - Archive all rows of schema.table read from db and record it in the history
+func takeSnapshot(db *sql.DB, cfg utils.Config, schema, table, reason string) (Snapshot, error) {
+	dir := snapshotDir(cfg)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Snapshot{}, err
+	}
+	index, err := loadIndex(dir)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	now := time.Now()
+	id := table + "-" + now.Format(snapshotTime)
+	for n := 2; hasSection(index, id); n++ {
+		id = table + "-" + now.Format(snapshotTime) + "-" + strconv.Itoa(n)
+	}
+	snap := Snapshot{
+		ID:      id,
+		Table:   schema + "." + table,
+		Profile: cfg.Profile,
+		Host:    cfg.Host + ":" + cfg.Port,
+		Time:    now,
+		File:    id + ".csv",
+		Reason:  reason,
+	}
+
+	query, err := snapshotSQL(cfg, schema, table)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	t := extract.Table{
+		Name:    "SNAPSHOT " + snap.Table,
+		SQL:     query,
+		Format:  extract.FormatCSV,
+		Timeout: cfg.QueryTimeout,
+	}
+	err = utils.WriteFileAtomic(filepath.Join(dir, snap.File), func(file io.Writer) error {
+		w := extract.NewCSVWriter(file)
+		w.Null = SnapshotNull
+		snap.Rows, err = extract.Extract(db, t, w)
+		return err
+	})
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	section := index.Section(id)
+	section.Key("table").SetValue(snap.Table)
+	section.Key("profile").SetValue(snap.Profile)
+	section.Key("host").SetValue(snap.Host)
+	section.Key("time").SetValue(now.Format("2006-01-02 15:04:05"))
+	section.Key("rows").SetValue(strconv.Itoa(snap.Rows))
+	section.Key("file").SetValue(snap.File)
+	section.Key("reason").SetValue(reason)
+	return snap, saveIndex(dir, index)
+}
+
+// TakeSnapshot - Read config p and archive the rows of table read through profile target.
+// Like a write the target must be named, so the snapshot records where it can be restored.
+func TakeSnapshot(p, target, table string) (Snapshot, error) {
+	// read config file
+	utils.WriteMsg("READ CONFIG")
+	cfg, err := utils.LoadTarget(p, target)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	utils.WriteMsg("TARGET: " + cfg.Profile + " (" + cfg.Host + ":" + cfg.Port + ")")
+	schema, name, err := utils.SplitTableName(cfg.Query(table))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	utils.WriteMsg("OPEN HDB")
+	db, err := cfg.Open()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer db.Close()
+
+	return takeSnapshot(db, cfg, schema, name, "manual")
+}
+
+// Restore - Read config p and replace the rows of the table of snapshot id with the archived
+// rows on target, the profile and host the snapshot was taken on. Snapshots without profile
+// are restored to any target on their host. The current rows are archived first, so a
+// restore can be undone as well.
+func Restore(p, target, id string, yes bool) error {
+	cfg, err := utils.LoadTarget(p, target)
+	if err != nil {
+		return err
+	}
+	dir := snapshotDir(cfg)
+	snaps, err := readSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	var snap *Snapshot
+	for i := range snaps {
+		if snaps[i].ID == id {
+			snap = &snaps[i]
+		}
+	}
+	if snap == nil {
+		return fmt.Errorf("no snapshot %s in %s", id, dir)
+	}
+	if snap.Profile != "" && snap.Profile != cfg.Profile {
+		return fmt.Errorf("snapshot %s was taken on profile %s, restore it with --target %s", id, snap.Profile, snap.Profile)
+	}
+	if host := cfg.Host + ":" + cfg.Port; snap.Host != host {
+		return fmt.Errorf("snapshot %s was taken on %s, target %s is %s", id, snap.Host, cfg.Profile, host)
+	}
+	utils.WriteMsg(fmt.Sprintf("RESTORE: %s, %d rows of %s taken %s", snap.ID, snap.Rows, snap.Table, snap.Time.Format("2006-01-02 15:04:05")))
+
+	return Run(p, Job{
+		Table:     snap.Table,
+		File:      filepath.Join(dir, snap.File),
+		Delimiter: "semicolon",
+		Target:    target,
+		Yes:       yes,
+		Mode:      ModeTruncate,
+		Null:      SnapshotNull,
+		Snapshot:  true,
+	})
+}
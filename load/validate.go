@@ -0,0 +1,161 @@
+package load
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+
+	hdb "github.com/SAP/go-hdb/driver"
+)
+
+// reject - CSV line that can not be loaded and why
+type reject struct {
+	line    int
+	values  []string
+	reasons []string
+}
+
+// required - col needs a value on insert
+func required(col utils.Column) bool {
+	return !col.Nullable && !col.Default
+}
+
+// checkRequired - Error naming the required columns of cols without a field
+func checkRequired(cols []utils.Column, fields []field) error {
+	var missing []string
+	for _, col := range cols {
+		if !required(col) {
+			continue
+		}
+		mapped := false
+		for _, f := range fields {
+			if f.col.Name == col.Name {
+				mapped = true
+				break
+			}
+		}
+		if !mapped {
+			missing = append(missing, col.Name)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("required columns not mapped: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validate - Coerced values of every line with width values for fields, and the lines
+// rejected with all their problems. A value equal to null, if not empty, is NULL, and
+// text escaped by extract.EscapeNull loses its escape.
+// Line numbers count from 1 in the file, including the header.
+func validate(fields []field, width int, lines [][]string, withHeader bool, null string) ([]record, []reject) {
+	first := 1
+	if withHeader {
+		first = 2
+	}
+	records := make([]record, 0, len(lines))
+	var rejects []reject
+	for i, line := range lines {
+		rec := record{line: first + i, values: make([]interface{}, len(fields))}
+		var reasons []string
+		if len(line) != width {
+			reasons = append(reasons, fmt.Sprintf("%d values, expected %d", len(line), width))
+		}
+		for j, f := range fields {
+			if f.src >= len(line) {
+				continue
+			}
+			if null != "" && line[f.src] == null {
+				if !f.col.Nullable {
+					reasons = append(reasons, f.col.Name+" can not be NULL")
+				}
+				continue
+			}
+			v, err := check(f.col, extract.UnescapeNull(line[f.src], null))
+			if err != nil {
+				reasons = append(reasons, err.Error())
+				continue
+			}
+			rec.values[j] = v
+		}
+		if len(reasons) != 0 {
+			rejects = append(rejects, reject{line: rec.line, values: line, reasons: reasons})
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, rejects
+}
+
+// check - Coerce s for col and check it against the catalog: required columns
+// need a value, text and binary must fit the length, decimals the precision.
+func check(col utils.Column, s string) (interface{}, error) {
+	if required(col) && strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("%s is required", col.Name)
+	}
+	v, err := Coerce(col, s)
+	if err != nil || v == nil {
+		return v, err
+	}
+	if col.Length <= 0 {
+		return v, nil
+	}
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		switch strings.ToUpper(col.Type) {
+		case "VARCHAR", "CHAR":
+			n = len(v)
+		}
+		if n > col.Length {
+			return nil, fmt.Errorf("%s: %d characters, max %d", col.Name, n, col.Length)
+		}
+	case []byte:
+		if strings.ToUpper(col.Type) != "BLOB" && len(v) > col.Length {
+			return nil, fmt.Errorf("%s: %d bytes, max %d", col.Name, len(v), col.Length)
+		}
+	case *hdb.Decimal:
+		r := (*big.Rat)(v)
+		digits := 0
+		if i := new(big.Int).Quo(r.Num(), r.Denom()); i.Sign() != 0 {
+			digits = len(i.Text(10))
+			if i.Sign() < 0 {
+				digits--
+			}
+		}
+		if strings.ToUpper(col.Type) == "DECIMAL" && digits > col.Length-col.Scale {
+			return nil, fmt.Errorf("%s: %s does not fit DECIMAL(%d,%d)", col.Name, s, col.Length, col.Scale)
+		}
+	}
+	return v, nil
+}
+
+// writeRejects - Write rejects to CSV file p with header, if any, and a REASON column
+func writeRejects(p string, comma rune, header []string, rejects []reject) error {
+	file, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	w.Comma = comma
+	if header != nil {
+		w.Write(append(append([]string{}, header...), "REASON"))
+	}
+	for _, r := range rejects {
+		w.Write(append(append([]string{}, r.values...), "line "+strconv.Itoa(r.line)+": "+strings.Join(r.reasons, "; ")))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
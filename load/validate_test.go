@@ -0,0 +1,88 @@
+package load
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+)
+
+func TestValidate(t *testing.T) {
+	fields := []field{
+		{col: utils.Column{Name: "CODE", Type: "NVARCHAR", Length: 4}, src: 0},
+		{col: utils.Column{Name: "AMOUNT", Type: "DECIMAL", Length: 5, Scale: 2, Nullable: true}, src: 1},
+		{col: utils.Column{Name: "NOTE", Type: "NVARCHAR", Length: 10, Nullable: true}, src: 2},
+	}
+	lines := [][]string{
+		{"A", "123.45", "x"},
+		{"B", "", ""},
+		{"C", `\N`, `\N`},
+		{"D", "", `\\N`},
+		{"", "1", "x"},
+		{"TOOLONG", "1", "x"},
+		{"E", "1234", "x"},
+		{"F", "0x10", "x"},
+		{"G", "1"},
+	}
+
+	t.Run("without NULL text", func(t *testing.T) {
+		records, rejects := validate(fields, 3, lines, true, "")
+		values := map[int][]interface{}{}
+		for _, rec := range records {
+			values[rec.line] = rec.values
+		}
+		if v := values[3]; v == nil || v[1] != nil || v[2] != "" {
+			t.Errorf("line 3 = %#v, want NULL decimal and empty text", v)
+		}
+		// \N is text without NULL text, and no decimal
+		if v := values[5]; v == nil || v[2] != `\\N` {
+			t.Errorf("line 5 = %#v, want text \\\\N", v)
+		}
+
+		reasons := map[int]string{}
+		for _, r := range rejects {
+			reasons[r.line] = strings.Join(r.reasons, "; ")
+		}
+		want := map[int]string{
+			4:  `AMOUNT: "\\N" is not a decimal`,
+			6:  "CODE is required",
+			7:  "CODE: 7 characters, max 4",
+			8:  "AMOUNT: 1234 does not fit DECIMAL(5,2)",
+			9:  `AMOUNT: "0x10" is not a decimal`,
+			10: "2 values, expected 3",
+		}
+		if !reflect.DeepEqual(reasons, want) {
+			t.Errorf("rejects %v, want %v", reasons, want)
+		}
+	})
+
+	t.Run("with NULL text", func(t *testing.T) {
+		records, _ := validate(fields, 3, lines[1:4], false, `\N`)
+		if len(records) != 3 {
+			t.Fatalf("%d records, want 3", len(records))
+		}
+		want := [][]interface{}{
+			{"B", nil, ""},
+			{"C", nil, nil},
+			// the escaped text loses its escape
+			{"D", nil, `\N`},
+		}
+		for i, rec := range records {
+			if rec.line != i+1 {
+				t.Errorf("record %d has line %d", i, rec.line)
+			}
+			if !reflect.DeepEqual(rec.values, want[i]) {
+				t.Errorf("line %d = %#v, want %#v", rec.line, rec.values, want[i])
+			}
+		}
+	})
+
+	t.Run("NULL text in a required column", func(t *testing.T) {
+		_, rejects := validate(fields, 3, [][]string{{`\N`, "1", "x"}}, false, `\N`)
+		if len(rejects) != 1 || rejects[0].reasons[0] != "CODE can not be NULL" {
+			t.Errorf("rejects %v, want CODE can not be NULL", rejects)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package perf
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/extract"
+	"github.com/morxs/go-hana/utils"
+)
+
+const (
+	// ExplainPlanSQL - Plan rows of a statement explained with statement name ?
+	ExplainPlanSQL = `select * from EXPLAIN_PLAN_TABLE where STATEMENT_NAME = ? order by OPERATOR_ID`
+	// PlanCacheSQL - Plan cache entries of statement hash ?
+	PlanCacheSQL = `select * from M_SQL_PLAN_CACHE where STATEMENT_HASH = ?`
+	// ExpensiveStatementsSQL - Latest recorded expensive executions of statement hash ?
+	ExpensiveStatementsSQL = `select top 100 * from M_EXPENSIVE_STATEMENTS where STATEMENT_HASH = ? order by START_TIME desc`
+)
+
+// Plan - Server side view of a query: its execution plan and the statistics HANA keeps for it
+type Plan struct {
+	Query         string `json:"query"`
+	StatementHash string `json:"statement_hash"`
+	// Plan - Rows of EXPLAIN_PLAN_TABLE
+	Plan []map[string]interface{} `json:"plan"`
+	// PlanCache - Rows of M_SQL_PLAN_CACHE
+	PlanCache []map[string]interface{} `json:"plan_cache"`
+	// ExpensiveStatements - Rows of M_EXPENSIVE_STATEMENTS, only filled when the expensive statements trace is on
+	ExpensiveStatements []map[string]interface{} `json:"expensive_statements"`
+	// Errors - Parts that could not be read, ie for missing privileges
+	Errors []string `json:"errors,omitempty"`
+}
+
+// StatementHash - Hash HANA keeps statement statistics under, the MD5 of the statement text
+func StatementHash(query string) string {
+	sum := md5.Sum([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Explain - Execution plan and statistics of q. Everything runs on one connection of db,
+// so the plan rows are read in the session that explained the statement.
+func Explain(db *sql.DB, q Query) Plan {
+	p := Plan{Query: q.Name, StatementHash: StatementHash(q.SQL)}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		p.Errors = append(p.Errors, "connect: "+err.Error())
+		return p
+	}
+	defer conn.Close()
+
+	name := fmt.Sprintf("perftest_%d", time.Now().UnixNano())
+	if _, err := conn.ExecContext(ctx, "explain plan set statement_name = '"+name+"' for "+q.SQL); err != nil {
+		p.Errors = append(p.Errors, "explain plan: "+err.Error())
+	} else {
+		if p.Plan, err = collect(ctx, conn, ExplainPlanSQL, name); err != nil {
+			p.Errors = append(p.Errors, "EXPLAIN_PLAN_TABLE: "+err.Error())
+		}
+		if _, err := conn.ExecContext(ctx, "delete from EXPLAIN_PLAN_TABLE where STATEMENT_NAME = ?", name); err != nil {
+			p.Errors = append(p.Errors, "EXPLAIN_PLAN_TABLE: "+err.Error())
+		}
+	}
+
+	if p.PlanCache, err = collect(ctx, conn, PlanCacheSQL, p.StatementHash); err != nil {
+		p.Errors = append(p.Errors, "M_SQL_PLAN_CACHE: "+err.Error())
+	}
+	if p.ExpensiveStatements, err = collect(ctx, conn, ExpensiveStatementsSQL, p.StatementHash); err != nil {
+		p.Errors = append(p.Errors, "M_EXPENSIVE_STATEMENTS: "+err.Error())
+	}
+	return p
+}
+
+// SavePlans - Write plans as indented JSON to p
+func SavePlans(p string, plans []Plan) error {
+	b, err := json.MarshalIndent(plans, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, append(b, '\n'), 0644)
+}
+
+// collect - Rows of query on conn as column name to value
+func collect(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var maps rowMaps
+	if _, err := extract.WriteRows(rows, extract.Table{}, &maps); err != nil {
+		return nil, err
+	}
+	if maps.rows == nil {
+		// an empty list rather than null in the file
+		maps.rows = []map[string]interface{}{}
+	}
+	return maps.rows, nil
+}
+
+// rowMaps - extract.Writer keeping rows as maps of JSON values, numbers stay numbers
+type rowMaps struct {
+	cols []extract.Column
+	rows []map[string]interface{}
+}
+
+func (r *rowMaps) WriteHeader(cols []extract.Column) error {
+	r.cols = cols
+	return nil
+}
+
+func (r *rowMaps) Write(values []interface{}) error {
+	m := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil, int64, float64, bool:
+			m[r.cols[i].Name] = v
+		case utils.Decimal:
+			if v.Kind == utils.DecimalFinite {
+				m[r.cols[i].Name] = json.Number(v.String())
+				continue
+			}
+			m[r.cols[i].Name] = v.String()
+		default:
+			m[r.cols[i].Name] = extract.FormatValue(r.cols[i], v)
+		}
+	}
+	r.rows = append(r.rows, m)
+	return nil
+}
+
+func (r *rowMaps) Close() error {
+	return nil
+}
@@ -0,0 +1,136 @@
+package perf
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Query - Query executed by a LoadTest
+type Query struct {
+	// Name - Name in reports, ie the query file
+	Name string
+	// SQL - Statement to execute
+	SQL string
+	// Args - Parameters bound to SQL
+	Args []interface{}
+	// Params - Values of the named parameters of the query file, see utils.BindNamed
+	Params map[string]string
+}
+
+// LoadTest - Executions of queries by concurrent workers
+type LoadTest struct {
+	Queries []Query
+	// Workers - Concurrent connections executing queries, at least 1
+	Workers int
+	// Warmup - Unmeasured executions of every query before the workers start
+	Warmup int
+	// Iterations - Measured executions of every query over all workers, used without Duration
+	Iterations int
+	// Duration - Start new executions until Duration has passed, 0 uses Iterations
+	Duration time.Duration
+	// RampUp - The workers start spread evenly over RampUp
+	RampUp time.Duration
+	// Timeout - Limit of every execution, 0 for none
+	Timeout time.Duration
+}
+
+// LoadResult - Statistics of a LoadTest
+type LoadResult struct {
+	// Queries - Statistics per query
+	Queries []Stats
+	// Total - Statistics of all executions
+	Total Stats
+	// Elapsed - From the start of the first worker to the end of the last
+	Elapsed time.Duration
+}
+
+// Run - Execute t on db. Queries are taken in turn, so every query gets the same share of executions.
+// Failed executions are logged and counted.
+func (t LoadTest) Run(db *sql.DB) LoadResult {
+	workers := t.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	db.SetMaxOpenConns(workers)
+	db.SetMaxIdleConns(workers)
+
+	for _, q := range t.Queries {
+		for i := 0; i < t.Warmup; i++ {
+			if _, err := executeTimeout(db, q.SQL, t.Timeout, q.Args...); err != nil {
+				log.Printf("WARMUP %s %d: %v", q.Name, i+1, err)
+			}
+		}
+	}
+
+	samples := make([][]Sample, len(t.Queries))
+	errors := make([]int, len(t.Queries))
+	var mu sync.Mutex
+	var issued int64
+
+	start := time.Now()
+	deadline := start.Add(t.Duration)
+	// next - Position in t.Queries of the next execution, -1 when the test is over
+	next := func() int {
+		if t.Duration > 0 && time.Now().After(deadline) {
+			return -1
+		}
+		n := atomic.AddInt64(&issued, 1)
+		if t.Duration <= 0 && n > int64(t.Iterations*len(t.Queries)) {
+			return -1
+		}
+		return int((n - 1) % int64(len(t.Queries)))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			if t.RampUp > 0 {
+				time.Sleep(t.RampUp * time.Duration(w) / time.Duration(workers))
+			}
+			for i := next(); i >= 0; i = next() {
+				q := t.Queries[i]
+				s, err := executeTimeout(db, q.SQL, t.Timeout, q.Args...)
+				mu.Lock()
+				if err != nil {
+					log.Printf("WORKER %d %s: %v", w+1, q.Name, err)
+					errors[i]++
+				} else {
+					samples[i] = append(samples[i], s)
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	r := LoadResult{Elapsed: time.Since(start)}
+	var all []Sample
+	allErrors := 0
+	for i, q := range t.Queries {
+		stats := Summarize(q.Name, samples[i], errors[i])
+		stats.QPS = qps(len(samples[i]), r.Elapsed)
+		stats.StatementHash = StatementHash(q.SQL)
+		r.Queries = append(r.Queries, stats)
+		all = append(all, samples[i]...)
+		allErrors += errors[i]
+	}
+	r.Total = Summarize("total", all, allErrors)
+	r.Total.QPS = qps(len(all), r.Elapsed)
+	if len(t.Queries) == 1 {
+		r.Total.StatementHash = r.Queries[0].StatementHash
+	}
+	return r
+}
+
+// qps - Successful executions per second of elapsed
+func qps(n int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
+}
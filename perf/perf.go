@@ -0,0 +1,90 @@
+package perf
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Sample - Timing of one execution of a query
+type Sample struct {
+	// FirstRow - From sending the query to the first row, or to the end of an empty result
+	FirstRow time.Duration
+	// Total - From sending the query to the last row fetched
+	Total time.Duration
+	// Rows - Rows fetched
+	Rows int64
+	// Bytes - Size of the values fetched, see valueSize
+	Bytes int64
+}
+
+// Execute - Run query on db once and fetch every row. ctx limits the whole execution.
+func Execute(ctx context.Context, db *sql.DB, query string, args ...interface{}) (Sample, error) {
+	var s Sample
+	start := time.Now()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return s, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return s, err
+	}
+	values := make([]interface{}, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if s.Rows == 0 {
+			s.FirstRow = time.Since(start)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return s, err
+		}
+		s.Rows++
+		for _, v := range values {
+			s.Bytes += valueSize(v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+	s.Total = time.Since(start)
+	if s.Rows == 0 {
+		s.FirstRow = s.Total
+	}
+	return s, nil
+}
+
+// executeTimeout - Execute limited to timeout if not 0
+func executeTimeout(db *sql.DB, query string, timeout time.Duration, args ...interface{}) (Sample, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return Execute(ctx, db, query, args...)
+}
+
+// valueSize - Approximate size of a fetched value: text and binary by length,
+// numbers and times by their fixed size, NULL as nothing
+func valueSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	case bool:
+		return 1
+	case time.Time:
+		return 8
+	}
+	return 8
+}
@@ -0,0 +1,38 @@
+package perf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// Report - Measurements of one perftest run
+type Report struct {
+	// Run - Id of the run in the results store, see Store
+	Run     string    `json:"run"`
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile"`
+	Host    string    `json:"host"`
+	Workers int       `json:"workers"`
+	Warmup  int       `json:"warmup"`
+	// Iterations - Requested executions of every query, 0 when run for Duration
+	Iterations int `json:"iterations"`
+	// Duration, RampUp and Elapsed in seconds
+	Duration float64 `json:"duration_s"`
+	RampUp   float64 `json:"ramp_up_s"`
+	Elapsed  float64 `json:"elapsed_s"`
+	Queries  []Stats `json:"queries"`
+	// Total - All queries together
+	Total Stats `json:"total"`
+	// Explain - File of the plans and server statistics of the queries, see SavePlans
+	Explain string `json:"explain,omitempty"`
+}
+
+// Save - Write r as indented JSON to p
+func (r Report) Save(p string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, append(b, '\n'), 0644)
+}
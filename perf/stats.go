@@ -0,0 +1,124 @@
+package perf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Distribution - Summary of durations in milliseconds
+type Distribution struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// Distribute - Distribution of d, zero without durations
+func Distribute(d []time.Duration) Distribution {
+	if len(d) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]time.Duration{}, d...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, v := range sorted {
+		sum += v
+	}
+	return Distribution{
+		Min:  millis(sorted[0]),
+		Mean: millis(sum / time.Duration(len(sorted))),
+		P50:  millis(percentile(sorted, 50)),
+		P95:  millis(percentile(sorted, 95)),
+		P99:  millis(percentile(sorted, 99)),
+		Max:  millis(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile - Nearest rank percentile p of sorted
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Stats - Statistics of the measured executions of one query
+type Stats struct {
+	// Query - Name of the query, ie its file
+	Query string `json:"query"`
+	// StatementHash - See StatementHash, empty for the total of several queries
+	StatementHash string `json:"statement_hash,omitempty"`
+	// Iterations - Measured executions, failed ones included
+	Iterations int `json:"iterations"`
+	// Errors - Failed executions
+	Errors int `json:"errors"`
+	// Rows - Rows of the last successful execution
+	Rows int64 `json:"rows"`
+	// FirstRow - Time to first row
+	FirstRow Distribution `json:"first_row_ms"`
+	// Total - Time to fetch all rows
+	Total Distribution `json:"total_ms"`
+	// RowsPerSec - Rows fetched per second of fetch time
+	RowsPerSec float64 `json:"rows_per_sec"`
+	// BytesPerSec - Bytes fetched per second of fetch time
+	BytesPerSec float64 `json:"bytes_per_sec"`
+	// QPS - Successful executions per second of the whole test
+	QPS float64 `json:"qps"`
+}
+
+// Summarize - Stats of the successful samples of query and the number of failed executions
+func Summarize(query string, samples []Sample, errors int) Stats {
+	s := Stats{Query: query, Iterations: len(samples) + errors, Errors: errors}
+	first := make([]time.Duration, len(samples))
+	total := make([]time.Duration, len(samples))
+	var fetch time.Duration
+	var rows, bytes int64
+	for i, sample := range samples {
+		first[i], total[i] = sample.FirstRow, sample.Total
+		fetch += sample.Total
+		rows += sample.Rows
+		bytes += sample.Bytes
+		s.Rows = sample.Rows
+	}
+	s.FirstRow = Distribute(first)
+	s.Total = Distribute(total)
+	if fetch > 0 {
+		s.RowsPerSec = float64(rows) / fetch.Seconds()
+		s.BytesPerSec = float64(bytes) / fetch.Seconds()
+	}
+	return s
+}
+
+// WriteTable - Write stats as a table of milliseconds for people
+func WriteTable(w io.Writer, stats []Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "query\ttiming (ms)\truns\terrors\tmin\tmean\tp50\tp95\tp99\tmax\trows\trows/s\tMB/s\tqps\t")
+	for _, s := range stats {
+		for _, t := range []struct {
+			name string
+			d    Distribution
+		}{{"first row", s.FirstRow}, {"total", s.Total}} {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%.0f\t%.2f\t%.2f\t\n",
+				s.Query, t.name, s.Iterations, s.Errors,
+				ms(t.d.Min), ms(t.d.Mean), ms(t.d.P50), ms(t.d.P95), ms(t.d.P99), ms(t.d.Max),
+				s.Rows, s.RowsPerSec, s.BytesPerSec/1e6, s.QPS)
+		}
+	}
+	return tw.Flush()
+}
+
+func ms(v float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0")
+}
@@ -0,0 +1,305 @@
+package perf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	// internal
+	"github.com/morxs/go-hana/utils"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// DefaultResultsDir - Results store used when config has none
+	DefaultResultsDir = "perfresults"
+	// resultsFile - Runs of the store, one Record per line
+	resultsFile = "results.jsonl"
+	// baselineFile - Baseline Record per Key
+	baselineFile = "baseline.json"
+	// runTime - Layout of the time in run ids
+	runTime = "20060102-150405"
+)
+
+// Record - Result of one query in one perftest run
+type Record struct {
+	// Run - Id of the run, YYYYMMDD-HHMMSS, shared by the queries executed together
+	Run           string    `json:"run"`
+	Time          time.Time `json:"time"`
+	Profile       string    `json:"profile"`
+	Host          string    `json:"host"`
+	Query         string    `json:"query"`
+	StatementHash string    `json:"statement_hash"`
+	// Params - Values of the named parameters of the query
+	Params     map[string]string `json:"params,omitempty"`
+	Workers    int               `json:"workers"`
+	Warmup     int               `json:"warmup"`
+	Iterations int               `json:"iterations"`
+	// Duration and Elapsed of the run in seconds
+	Duration float64 `json:"duration_s"`
+	Elapsed  float64 `json:"elapsed_s"`
+	Stats    Stats   `json:"stats"`
+}
+
+// Key - Identity of the measured work: the statement, its parameters and the host it ran on.
+// Only records of the same key are compared.
+func (r Record) Key() string {
+	names := make([]string, 0, len(r.Params))
+	for name := range r.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	key := r.StatementHash + " " + r.Host
+	for _, name := range names {
+		key += " " + name + "=" + r.Params[name]
+	}
+	return key
+}
+
+// Records - One Record per query of r, params per query name
+func Records(r Report, params map[string]map[string]string) []Record {
+	var records []Record
+	for _, s := range r.Queries {
+		records = append(records, Record{
+			Run:           r.Run,
+			Time:          r.Time,
+			Profile:       r.Profile,
+			Host:          r.Host,
+			Query:         s.Query,
+			StatementHash: s.StatementHash,
+			Params:        params[s.Query],
+			Workers:       r.Workers,
+			Warmup:        r.Warmup,
+			Iterations:    r.Iterations,
+			Duration:      r.Duration,
+			Elapsed:       r.Elapsed,
+			Stats:         s,
+		})
+	}
+	return records
+}
+
+// Store - Directory of the records of every run and the baselines they are compared against
+type Store struct {
+	Dir string
+}
+
+// ResultsDir - Store directory dir, DefaultResultsDir if empty
+func ResultsDir(dir string) Store {
+	if dir == "" {
+		dir = DefaultResultsDir
+	}
+	return Store{Dir: dir}
+}
+
+// LoadStore - Store of config p, [perftest] results_dir. Only that section is read, so runs
+// are compared without a connection or its credentials.
+func LoadStore(p string) (Store, error) {
+	if p == "" {
+		p = "config.ini"
+	}
+	iniCfg, err := ini.Load(p)
+	if err != nil {
+		return Store{}, err
+	}
+	return ResultsDir(iniCfg.Section("perftest").Key("results_dir").String()), nil
+}
+
+// NewRun - Unused run id for a run started at t
+func (s Store) NewRun(t time.Time) (string, error) {
+	records, err := s.Records()
+	if err != nil {
+		return "", err
+	}
+	used := map[string]bool{}
+	for _, r := range records {
+		used[r.Run] = true
+	}
+	id := t.Format(runTime)
+	for n := 2; used[id]; n++ {
+		id = t.Format(runTime) + "-" + strconv.Itoa(n)
+	}
+	return id, nil
+}
+
+// Append - Add records to the store
+func (s Store) Append(records []Record) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.Dir, resultsFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Records - Every record of the store, oldest first. A missing store is empty.
+func (s Store) Records() ([]Record, error) {
+	f, err := os.Open(filepath.Join(s.Dir, resultsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records []Record
+	dec := json.NewDecoder(f)
+	for {
+		var r Record
+		if err := dec.Decode(&r); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name(), err)
+		}
+		records = append(records, r)
+	}
+}
+
+// Run - Records of run id, the latest run if id is empty
+func (s Store) Run(id string) ([]Record, error) {
+	records, err := s.Records()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		if len(records) == 0 {
+			return nil, fmt.Errorf("no runs in %s", s.Dir)
+		}
+		id = records[len(records)-1].Run
+	}
+	var run []Record
+	for _, r := range records {
+		if r.Run == id {
+			run = append(run, r)
+		}
+	}
+	if len(run) == 0 {
+		return nil, fmt.Errorf("run %s not in %s", id, s.Dir)
+	}
+	return run, nil
+}
+
+// Baselines - Baseline record per Key, empty if none was set
+func (s Store) Baselines() (map[string]Record, error) {
+	base := map[string]Record{}
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, baselineFile))
+	if os.IsNotExist(err) {
+		return base, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &base); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(s.Dir, baselineFile), err)
+	}
+	return base, nil
+}
+
+// SetBaseline - Make records the baseline of their keys, the baselines of other keys are kept
+func (s Store) SetBaseline(records []Record) error {
+	base, err := s.Baselines()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		base[r.Key()] = r
+	}
+	b, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	p := filepath.Join(s.Dir, baselineFile)
+	// through a temporary file so a crash never loses the baselines
+	return utils.WriteFileAtomic(p, func(w io.Writer) error {
+		_, err := w.Write(append(b, '\n'))
+		return err
+	})
+}
+
+// Comparison - p95 of the total time of a record against its baseline
+type Comparison struct {
+	Current Record
+	// Baseline - nil when there is none for the key of Current
+	Baseline *Record
+	// Change - Increase of p95 in percent of the baseline
+	Change float64
+	// Regressed - Change is beyond the threshold
+	Regressed bool
+}
+
+// Compare - Compare run with base, a p95 more than threshold percent above its baseline is a regression
+func Compare(run []Record, base map[string]Record, threshold float64) []Comparison {
+	var cmps []Comparison
+	for _, r := range run {
+		c := Comparison{Current: r}
+		if b, ok := base[r.Key()]; ok {
+			c.Baseline = &b
+			if b.Stats.Total.P95 > 0 {
+				c.Change = (r.Stats.Total.P95 - b.Stats.Total.P95) / b.Stats.Total.P95 * 100
+			}
+			// a run without a successful execution has no p95 to compare
+			c.Regressed = c.Change > threshold || (r.Stats.Iterations > 0 && r.Stats.Errors == r.Stats.Iterations)
+		}
+		cmps = append(cmps, c)
+	}
+	return cmps
+}
+
+// RunBaseline - Records of run as baseline per Key, to compare against a run other than the baseline
+func RunBaseline(run []Record) map[string]Record {
+	base := map[string]Record{}
+	for _, r := range run {
+		base[r.Key()] = r
+	}
+	return base
+}
+
+// WriteComparison - Write cmps as a table for people
+func WriteComparison(w io.Writer, cmps []Comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "query\thost\tparams\tbaseline\tp95 (ms)\trun\tp95 (ms)\tchange\t")
+	for _, c := range cmps {
+		r := c.Current
+		if c.Baseline == nil {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t-\t-\t%s\t%s\tno baseline\t\n",
+				r.Query, r.Host, params(r.Params), r.Run, ms(r.Stats.Total.P95))
+			continue
+		}
+		status := fmt.Sprintf("%+.1f%%", c.Change)
+		if c.Regressed {
+			status += " REGRESSED"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			r.Query, r.Host, params(r.Params), c.Baseline.Run, ms(c.Baseline.Stats.Total.P95), r.Run, ms(r.Stats.Total.P95), status)
+	}
+	return tw.Flush()
+}
+
+// params - Parameters as sorted name=value list, - without any
+func params(p map[string]string) string {
+	if len(p) == 0 {
+		return "-"
+	}
+	return strings.Replace(utils.ParamList(p), ", ", ",", -1)
+}
@@ -1,3 +1,7 @@
+-- slow moving stock of a company, run with --param company=AN --param start_period=20170101
+-- @param company string AN
+-- @param start_period date 20170101
+-- @param end_period date 20171231
 SELECT
 Table__1."BUKRS",
 Table__1."BUTXT",
@@ -62,9 +66,9 @@ FROM
       , "MBLNR_ISSUE"
       , "MJAHR_ISSUE"
     from "_SYS_BIC"."wip-slowmove/CA_SLOW_MOVE8"
-    ('PLACEHOLDER' = ('$$company$$', 'AN', 
-                      '$$start_period$$', '20170101', 
-                      '$$end_period$$', '20171231')) 
+    ('PLACEHOLDER' = ('$$company$$', '{{company}}', 
+                      '$$start_period$$', '{{start_period}}', 
+                      '$$end_period$$', '{{end_period}}')) 
 ORDER BY "BUKRS" , "WERKS" , "LGORT" , "MATNR"
 )  Table__1
 WHERE
@@ -73,7 +77,7 @@ WHERE
    AND
    Table__1."CLBAL_BS" > 0 
    AND
-   Table__1."MANDT" = '777'
+   Table__1."MANDT" = $$client$$
    AND
    Table__1."MTART" = 'SPAR'
   )
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// TableColumnsSQL - Column metadata of a table from HANA catalog
+	TableColumnsSQL = `select
+COLUMN_NAME
+, DATA_TYPE_NAME
+, LENGTH
+, SCALE
+, IS_NULLABLE
+, DEFAULT_VALUE
+from SYS.TABLE_COLUMNS
+where SCHEMA_NAME = ?
+and TABLE_NAME = ?
+order by POSITION`
+
+	// PrimaryKeySQL - Primary key columns of a table from HANA catalog
+	PrimaryKeySQL = `select
+COLUMN_NAME
+from SYS.CONSTRAINTS
+where SCHEMA_NAME = ?
+and TABLE_NAME = ?
+and IS_PRIMARY_KEY = 'TRUE'
+order by POSITION`
+)
+
+var plainIdentifier = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// Column - Column metadata as stored in SYS.TABLE_COLUMNS
+type Column struct {
+	Name     string
+	Type     string
+	Length   int
+	Scale    int
+	Nullable bool
+	// Default - The column has a default value, so an insert may leave it out
+	Default bool
+}
+
+// SplitTableName - Split SCHEMA.TABLE into schema and table, both upper case
+func SplitTableName(s string) (string, string, error) {
+	st := strings.SplitN(s, ".", 2)
+	if len(st) != 2 || st[0] == "" || st[1] == "" {
+		return "", "", fmt.Errorf("invalid table name %q, expected SCHEMA.TABLE", s)
+	}
+	return strings.ToUpper(st[0]), strings.ToUpper(st[1]), nil
+}
+
+// TableColumns - Read column metadata of schema.table from HANA catalog
+func TableColumns(db *sql.DB, schema, table string) ([]Column, error) {
+	rows, err := db.Query(TableColumnsSQL, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []Column
+	for rows.Next() {
+		var col Column
+		var length, scale sql.NullInt64
+		var nullable string
+		var def sql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &length, &scale, &nullable, &def); err != nil {
+			return nil, err
+		}
+		col.Length = int(length.Int64)
+		col.Scale = int(scale.Int64)
+		col.Nullable = nullable == "TRUE"
+		col.Default = def.Valid
+		cols = append(cols, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("table %s.%s not found or has no columns", schema, table)
+	}
+	return cols, nil
+}
+
+// PrimaryKey - Read the primary key columns of schema.table from HANA catalog, none without primary key
+func PrimaryKey(db *sql.DB, schema, table string) ([]string, error) {
+	rows, err := db.Query(PrimaryKeySQL, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		keys = append(keys, name)
+	}
+	return keys, rows.Err()
+}
+
+// QuoteIdentifier - Quote identifier for SQL when it is not a plain upper case name, ie "/BEV1/LULEINH"
+func QuoteIdentifier(s string) string {
+	if plainIdentifier.MatchString(s) {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// CompanyPlaceholder - Marks the company list in a query, ie bukrs in ($$coy$$).
+	// It is expanded into one ? per company code, the codes are bound as parameters.
+	CompanyPlaceholder = "$$coy$$"
+
+	// DefaultRegion - Company group used when config.ini has no [region] default
+	DefaultRegion = "africa"
+
+	// T001CompaniesSQL - Company codes of T001, to validate the selected ones
+	T001CompaniesSQL = `select
+BUKRS
+from $$schema$$.t001
+where mandt = $$client$$
+and bukrs in ($$coy$$)`
+)
+
+// DefaultRegions - Company groups used when config.ini has no [region] section
+var DefaultRegions = map[string][]string{
+	"indo": {"BM", "BO", "CL", "DE", "EB", "EC", "EE", "EL", "EP", "ES", "FB", "FM", "GM", "GU", "HM", "JW", "KI", "KM", "NE", "NO", "NS", "NX", "OE", "PB", "PE", "PO", "RB", "RH", "RM", "SE", "SF", "SG", "SH", "SO", "SU", "VI", "WH",
+		"AA", "AD", "AG", "AJ", "AN", "AP", "BN", "BV", "BW", "BX", "BY", "CA", "CC", "CX", "DA",
+		"DB", "DC", "DG", "DI", "GA", "GK", "IA", "ID", "IE", "IF", "KD", "KF", "KG", "MD", "MF", "MH",
+		"MJ", "MO", "NI", "PA", "PF", "PR", "PT", "PV", "PX", "RA", "RJ",
+		"SB", "SJ", "SN", "SV", "SX", "TB", "TC", "TM", "TN", "UD", "UI", "WJ"},
+	"africa": {"BC", "EY", "BZ", "OU"},
+}
+
+// loadRegions - Company groups of the [region] section, from [region] file if given.
+// The keys default and file are settings, every other key is a group.
+func loadRegions(cfg *Config, iniCfg *ini.File, p string) error {
+	section := iniCfg.Section("region")
+	cfg.Region = section.Key("default").MustString(DefaultRegion)
+
+	if file := section.Key("file").String(); file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(p), file)
+		}
+		regionCfg, err := ini.Load(file)
+		if err != nil {
+			return err
+		}
+		section = regionCfg.Section("region")
+	}
+
+	cfg.Regions = make(map[string][]string)
+	for _, key := range section.Keys() {
+		if key.Name() == "default" || key.Name() == "file" {
+			continue
+		}
+		cfg.Regions[strings.ToLower(key.Name())] = SplitList(key.String())
+	}
+	if len(cfg.Regions) == 0 {
+		cfg.Regions = DefaultRegions
+	}
+	return nil
+}
+
+// SplitList - Split comma separated company codes, upper case without blanks
+func SplitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.ToUpper(strings.TrimSpace(v)); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Companies - Company codes to extract: bukrs if given, else the codes of region or [region] default
+func (cfg Config) Companies(region string, bukrs []string) ([]string, error) {
+	if len(bukrs) != 0 {
+		return bukrs, nil
+	}
+	if region == "" {
+		region = cfg.Region
+	}
+	codes, ok := cfg.Regions[strings.ToLower(region)]
+	if !ok {
+		var names []string
+		for name := range cfg.Regions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown region %q, expected one of %s", region, strings.Join(names, ", "))
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("region %q has no company codes", region)
+	}
+	return codes, nil
+}
+
+// ValidateCompanies - Check that every company code of bukrs exists in T001
+func (cfg Config) ValidateCompanies(db *sql.DB, bukrs []string) error {
+	query, args, err := cfg.Prepare(T001CompaniesSQL, nil, bukrs)
+	if err != nil {
+		return err
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return err
+		}
+		found[code] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, code := range bukrs {
+		if !found[code] {
+			missing = append(missing, code)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("company code not found in T001: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DecimalSize - Size of HANA DECIMAL (IEEE 754 decimal128) in bytes
+	DecimalSize = 16
+)
+
+const (
+	// DecimalFinite - Regular decimal value
+	DecimalFinite = iota
+	// DecimalInf - Positive or negative infinity
+	DecimalInf
+	// DecimalNaN - Not a number
+	DecimalNaN
+)
+
+var bigTen = big.NewInt(10)
+
+// decimalText - Plain decimal notation. big.Rat also takes fractions, exponents, 0x, 0b and 0o
+// prefixes and _ between digits, none of which is a decimal of a file or a parameter.
+var decimalText = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+// ParseDecimalText - Exact value of s in plain decimal notation, ie -12.30, false for anything else
+func ParseDecimalText(s string) (*big.Rat, bool) {
+	if !decimalText.MatchString(s) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// Decimal - Exact value of HANA DECIMAL, Mantissa * 10^Exp
+type Decimal struct {
+	Neg      bool
+	Mantissa *big.Int
+	Exp      int
+	// Kind - DecimalFinite, DecimalInf or DecimalNaN
+	Kind int
+}
+
+// ParseDecimal - Decode HANA DECIMAL bytes without losing precision
+func ParseDecimal(b []byte) (Decimal, error) {
+	var d Decimal
+	if len(b) != DecimalSize {
+		return d, fmt.Errorf("decimal: invalid size %d - %d expected", len(b), DecimalSize)
+	}
+
+	d.Neg = (b[15] & 0x80) != 0
+	switch {
+	case (b[15] & 0x7C) == 0x7C:
+		d.Kind = DecimalNaN
+		return d, nil
+	case (b[15] & 0x78) == 0x78:
+		d.Kind = DecimalInf
+		return d, nil
+	case (b[15] & 0x60) == 0x60:
+		// coefficient would exceed 34 digits, not produced by HANA
+		return d, fmt.Errorf("decimal: unsupported format %v", b)
+	}
+
+	// DecodeDecimal touches b[14] while decoding, so work on a copy
+	c := make([]byte, DecimalSize)
+	copy(c, b)
+	d.Mantissa = new(big.Int)
+	d.Neg, d.Exp = DecodeDecimal(c, d.Mantissa)
+	return d, nil
+}
+
+// IsZero - True if d is a finite zero
+func (d Decimal) IsZero() bool {
+	return d.Kind == DecimalFinite && (d.Mantissa == nil || d.Mantissa.Sign() == 0)
+}
+
+// Rat - Convert finite d into big.Rat, nil for NaN and infinity
+func (d Decimal) Rat() *big.Rat {
+	if d.Kind != DecimalFinite {
+		return nil
+	}
+	r := new(big.Rat)
+	if d.Mantissa != nil {
+		r.SetInt(d.Mantissa)
+	}
+	p := new(big.Int).Exp(bigTen, big.NewInt(int64(abs(d.Exp))), nil)
+	if d.Exp < 0 {
+		r.Quo(r, new(big.Rat).SetInt(p))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(p))
+	}
+	if d.Neg {
+		r.Neg(r)
+	}
+	return r
+}
+
+// String - Exact decimal representation, ie 1230 * 10^-2 gives 12.30
+func (d Decimal) String() string {
+	if s, ok := d.special(); ok {
+		return s
+	}
+	return d.format(d.Mantissa, d.Exp)
+}
+
+// StringFixed - Decimal representation with exactly scale digits after the point.
+// Extra digits are rounded half away from zero.
+func (d Decimal) StringFixed(scale int) string {
+	return d.Round(scale).String()
+}
+
+// Round - Rescale d to exactly scale digits after the point, rounding half away from zero
+func (d Decimal) Round(scale int) Decimal {
+	if d.Kind != DecimalFinite {
+		return d
+	}
+	if scale < 0 {
+		scale = 0
+	}
+
+	m := new(big.Int)
+	if d.Mantissa != nil {
+		m.Set(d.Mantissa)
+	}
+	shift := d.Exp + scale
+	switch {
+	case shift > 0:
+		m.Mul(m, new(big.Int).Exp(bigTen, big.NewInt(int64(shift)), nil))
+	case shift < 0:
+		p := new(big.Int).Exp(bigTen, big.NewInt(int64(-shift)), nil)
+		r := new(big.Int)
+		m.QuoRem(m, p, r)
+		// round half away from zero
+		if r.Lsh(r, 1).Cmp(p) >= 0 {
+			m.Add(m, big.NewInt(1))
+		}
+	}
+	return Decimal{Neg: d.Neg, Mantissa: m, Exp: -scale}
+}
+
+func (d Decimal) special() (string, bool) {
+	switch d.Kind {
+	case DecimalNaN:
+		return "NaN", true
+	case DecimalInf:
+		if d.Neg {
+			return "-Infinity", true
+		}
+		return "Infinity", true
+	}
+	return "", false
+}
+
+// format - Render m * 10^exp with sign of d
+func (d Decimal) format(m *big.Int, exp int) string {
+	digits := "0"
+	if m != nil {
+		digits = m.String()
+	}
+
+	var s string
+	switch {
+	case exp >= 0:
+		if digits != "0" {
+			s = digits + strings.Repeat("0", exp)
+		} else {
+			s = digits
+		}
+	default:
+		n := -exp
+		if len(digits) <= n {
+			digits = strings.Repeat("0", n-len(digits)+1) + digits
+		}
+		s = digits[:len(digits)-n] + "." + digits[len(digits)-n:]
+	}
+
+	if d.Neg && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// NullDecimal - Nullable HANA DECIMAL
+type NullDecimal struct {
+	Decimal
+	Valid bool
+}
+
+// Scan implements the Scanner interface.
+func (nd *NullDecimal) Scan(value interface{}) error {
+	if value == nil {
+		nd.Decimal, nd.Valid = Decimal{}, false
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("decimal: invalid data type %T", value)
+	}
+	d, err := ParseDecimal(b)
+	if err != nil {
+		return err
+	}
+	nd.Decimal, nd.Valid = d, true
+	return nil
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		name  string
+		b     []byte
+		kind  int
+		str   string
+		scale int
+		fixed string
+	}{
+		{
+			name: "zero",
+			b:    []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x30},
+			str:  "0", scale: 2, fixed: "0.00",
+		},
+		{
+			name: "negative",
+			b:    []byte{0xce, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0xb0},
+			str:  "-12.30", scale: 1, fixed: "-12.3",
+		},
+		{
+			name: "large positive exponent",
+			b:    []byte{0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x68, 0x30},
+			str:  "500000000000000000000", scale: 2, fixed: "500000000000000000000.00",
+		},
+		{
+			name: "large negative exponent",
+			b:    []byte{0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0xb0},
+			str:  "-0.000000000000000000000000000007", scale: 4, fixed: "0.0000",
+		},
+		{
+			name: "maximum mantissa",
+			b:    []byte{0xff, 0xff, 0xff, 0xff, 0x63, 0x8e, 0x8d, 0x37, 0xc0, 0x87, 0xad, 0xbe, 0x09, 0xed, 0x41, 0x30},
+			str:  "9999999999999999999999999999999999", scale: 0, fixed: "9999999999999999999999999999999999",
+		},
+		{
+			name: "maximum mantissa all fraction",
+			b:    []byte{0xff, 0xff, 0xff, 0xff, 0x63, 0x8e, 0x8d, 0x37, 0xc0, 0x87, 0xad, 0xbe, 0x09, 0xed, 0xfd, 0xaf},
+			str:  "-0.9999999999999999999999999999999999", scale: 4, fixed: "-1.0000",
+		},
+		{
+			name: "tie rounds up",
+			b:    []byte{0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x36, 0x30},
+			str:  "0.00005", scale: 4, fixed: "0.0001",
+		},
+		{
+			name: "negative tie rounds away from zero",
+			b:    []byte{0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x36, 0xb0},
+			str:  "-0.00005", scale: 4, fixed: "-0.0001",
+		},
+		{
+			name: "tie with carry",
+			b:    []byte{0x35, 0xe2, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x36, 0x30},
+			str:  "1.23445", scale: 4, fixed: "1.2345",
+		},
+		{
+			name: "odd tie",
+			b:    []byte{0x7d, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x30},
+			str:  "1.25", scale: 1, fixed: "1.3",
+		},
+		{
+			name: "negative below half loses sign",
+			b:    []byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x36, 0xb0},
+			str:  "-0.00004", scale: 4, fixed: "0.0000",
+		},
+		{
+			name: "NaN",
+			b:    []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7c},
+			kind: DecimalNaN, str: "NaN", scale: 2, fixed: "NaN",
+		},
+		{
+			name: "infinity",
+			b:    []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x78},
+			kind: DecimalInf, str: "Infinity", scale: 2, fixed: "Infinity",
+		},
+		{
+			name: "negative infinity",
+			b:    []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8},
+			kind: DecimalInf, str: "-Infinity", scale: 2, fixed: "-Infinity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := append([]byte{}, tt.b...)
+			d, err := ParseDecimal(b)
+			if err != nil {
+				t.Fatalf("ParseDecimal: %v", err)
+			}
+			if !bytes.Equal(b, tt.b) {
+				t.Errorf("ParseDecimal changed its input to %x", b)
+			}
+			if d.Kind != tt.kind {
+				t.Errorf("Kind = %d, want %d", d.Kind, tt.kind)
+			}
+			if s := d.String(); s != tt.str {
+				t.Errorf("String() = %q, want %q", s, tt.str)
+			}
+			if s := d.StringFixed(tt.scale); s != tt.fixed {
+				t.Errorf("StringFixed(%d) = %q, want %q", tt.scale, s, tt.fixed)
+			}
+
+			r := d.Round(tt.scale)
+			if r.Kind != tt.kind {
+				t.Errorf("Round(%d).Kind = %d, want %d", tt.scale, r.Kind, tt.kind)
+			}
+			if tt.kind == DecimalFinite && r.Exp != -tt.scale {
+				t.Errorf("Round(%d).Exp = %d, want %d", tt.scale, r.Exp, -tt.scale)
+			}
+			if s := r.String(); s != tt.fixed {
+				t.Errorf("Round(%d).String() = %q, want %q", tt.scale, s, tt.fixed)
+			}
+			// rounding again to the same scale changes nothing
+			if s := r.Round(tt.scale).String(); s != tt.fixed {
+				t.Errorf("Round(%d) twice = %q, want %q", tt.scale, s, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestParseDecimalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"short", make([]byte, DecimalSize-1)},
+		{"long", make([]byte, DecimalSize+1)},
+		{"large coefficient format", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x60}},
+	}
+	for _, tt := range tests {
+		if _, err := ParseDecimal(tt.b); err == nil {
+			t.Errorf("%s: ParseDecimal(%x) gave no error", tt.name, tt.b)
+		}
+	}
+}
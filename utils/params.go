@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	hdb "github.com/SAP/go-hdb/driver"
+	"github.com/go-ini/ini"
+)
+
+// Types of query parameters
+const (
+	ParamString  = "string"
+	ParamInt     = "int"
+	ParamDecimal = "decimal"
+	// ParamDate - SAP date, given as YYYYMMDD or YYYY-MM-DD and used as YYYYMMDD
+	ParamDate = "date"
+)
+
+// ParamTypes - Valid types of query parameters
+var ParamTypes = []string{ParamString, ParamInt, ParamDecimal, ParamDate}
+
+var (
+	// paramHeader - Declaration of a parameter in the header of a query file:
+	// -- @param <name> <type> [default]
+	paramHeader = regexp.MustCompile(`^--\s*@param\s+(\S+)\s+(\S+)(?:\s+(.*))?$`)
+	paramName   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Param - Named parameter of a query, written {{name}} in the query
+type Param struct {
+	Name string
+	// Type - One of ParamTypes
+	Type string
+	// Default - Value used when none is given, only if HasDefault
+	Default    string
+	HasDefault bool
+}
+
+// Value - Check v against the type of p, the checked text and the value to bind
+func (p Param) Value(v string) (string, interface{}, error) {
+	switch p.Type {
+	case ParamInt:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("parameter %s: %q is not an int", p.Name, v)
+		}
+		return strconv.FormatInt(i, 10), i, nil
+	case ParamDecimal:
+		r, ok := ParseDecimalText(strings.TrimSpace(v))
+		if !ok {
+			return "", nil, fmt.Errorf("parameter %s: %q is not a decimal", p.Name, v)
+		}
+		return strings.TrimSpace(v), (*hdb.Decimal)(r), nil
+	case ParamDate:
+		v = strings.TrimSpace(v)
+		t, err := time.Parse("20060102", v)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", v); err != nil {
+				return "", nil, fmt.Errorf("parameter %s: %q is not a date, use YYYYMMDD or YYYY-MM-DD", p.Name, v)
+			}
+		}
+		return t.Format("20060102"), t.Format("20060102"), nil
+	}
+	return v, v, nil
+}
+
+// QueryParams - Parameters of query: the ones declared in its header, then the other {{name}}
+// of the query as strings without default, each in the order of first appearance
+func QueryParams(query string) ([]Param, error) {
+	var params []Param
+	declared := map[string]bool{}
+	for _, line := range strings.Split(query, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			// the header ends with the first line of SQL
+			break
+		}
+		m := paramHeader.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		p := Param{Name: m[1], Type: strings.ToLower(m[2])}
+		if !paramName.MatchString(p.Name) {
+			return nil, fmt.Errorf("invalid parameter name %q", p.Name)
+		}
+		if !validParamType(p.Type) {
+			return nil, fmt.Errorf("parameter %s: invalid type %q, use %s", p.Name, m[2], strings.Join(ParamTypes, ", "))
+		}
+		if declared[p.Name] {
+			return nil, fmt.Errorf("parameter %s declared twice", p.Name)
+		}
+		if m[3] != "" {
+			p.Default, p.HasDefault = strings.TrimSpace(m[3]), true
+			if _, _, err := p.Value(p.Default); err != nil {
+				return nil, fmt.Errorf("default of %v", err)
+			}
+		}
+		declared[p.Name] = true
+		params = append(params, p)
+	}
+
+	_, err := scanParams(query, func(name string, quote byte) string {
+		if !declared[name] {
+			declared[name] = true
+			params = append(params, Param{Name: name, Type: ParamString})
+		}
+		return ""
+	})
+	return params, err
+}
+
+func validParamType(t string) bool {
+	for _, v := range ParamTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// BindNamed - Replace the {{name}} of query with the checked values of the named parameters.
+// Outside quotes they become ? and the value is bound, inside a quoted literal, ie the values of
+// a calculation view 'PLACEHOLDER', the text is written into the literal with its quotes doubled.
+// Returns the query, its arguments in the order of ? and the text of every value used.
+// Values of parameters the query has not got are left alone, see UnusedParams.
+func BindNamed(query string, values map[string]string) (string, []interface{}, map[string]string, error) {
+	params, err := QueryParams(query)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	texts := map[string]string{}
+	bound := map[string]interface{}{}
+	var missing []string
+	for _, p := range params {
+		v, ok := values[p.Name]
+		if !ok && !p.HasDefault {
+			missing = append(missing, p.Name)
+			continue
+		}
+		if !ok {
+			v = p.Default
+		}
+		if texts[p.Name], bound[p.Name], err = p.Value(v); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	if len(missing) > 0 {
+		return "", nil, nil, fmt.Errorf("query needs a value for %s, use --param name=value", strings.Join(missing, ", "))
+	}
+
+	var args []interface{}
+	query, err = scanParams(query, func(name string, quote byte) string {
+		if quote == 0 {
+			args = append(args, bound[name])
+			return "?"
+		}
+		q := string(quote)
+		return strings.Replace(texts[name], q, q+q, -1)
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(params) == 0 {
+		texts = nil
+	}
+	return query, args, texts, nil
+}
+
+// scanParams - query with every {{name}} outside comments replaced by f of the name
+// and the quote it is in, 0 for none
+func scanParams(query string, f func(name string, quote byte) string) (string, error) {
+	return scanSQL(query, func(rest string, quote byte) (string, int, error) {
+		if !strings.HasPrefix(rest, "{{") {
+			return "", 0, nil
+		}
+		end := strings.Index(rest, "}}")
+		if end < 0 {
+			return "", 0, fmt.Errorf("unterminated parameter at %q", firstLine(rest))
+		}
+		name := strings.TrimSpace(rest[2:end])
+		if !paramName.MatchString(name) {
+			return "", 0, fmt.Errorf("invalid parameter name %q", name)
+		}
+		return f(name, quote), end + 2, nil
+	})
+}
+
+// scanSQL - Copy of query where f may replace text outside comments. f gets the rest of the
+// query at each byte that is neither a comment nor a quote, and the quote it is in, 0 for none.
+// It returns the text to write instead of the first n bytes of rest, n 0 copies the byte.
+func scanSQL(query string, f func(rest string, quote byte) (string, int, error)) (string, error) {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			// '' inside a literal closes and reopens it, nothing to do
+			if c == quote {
+				quote = 0
+				b.WriteByte(c)
+				continue
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			b.WriteByte(c)
+			continue
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated comment")
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 3
+			continue
+		}
+		text, n, err := f(query[i:], quote)
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString(text)
+		i += n - 1
+	}
+	return b.String(), nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// ReadParams - Values of named parameters, from the [params] section or the keys outside of any
+// section of ini file p if not empty, then from pairs name=value which win over the file
+func ReadParams(p string, pairs []string) (map[string]string, error) {
+	values := map[string]string{}
+	if p != "" {
+		iniCfg, err := ini.Load(p)
+		if err != nil {
+			return nil, err
+		}
+		section := iniCfg.Section("")
+		if s, err := iniCfg.GetSection("params"); err == nil {
+			section = s
+		}
+		for _, key := range section.Keys() {
+			values[key.Name()] = key.String()
+		}
+	}
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid parameter, expected name=value: %s", pair)
+		}
+		values[strings.TrimSpace(kv[0])] = kv[1]
+	}
+	return values, nil
+}
+
+// UnusedParams - Names of values no query has a parameter for, sorted. used holds the
+// value texts of every query as returned by BindNamed.
+func UnusedParams(values map[string]string, used ...map[string]string) []string {
+	var unused []string
+	for name := range values {
+		found := false
+		for _, u := range used {
+			if _, ok := u[name]; ok {
+				found = true
+			}
+		}
+		if !found {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// ParamList - values as name=value separated by comma, sorted by name
+func ParamList(values map[string]string) string {
+	var list []string
+	for name, value := range values {
+		list = append(list, name+"="+value)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	hdb "github.com/SAP/go-hdb/driver"
+)
+
+func TestParamValue(t *testing.T) {
+	tests := []struct {
+		p    Param
+		in   string
+		text string
+		want interface{}
+	}{
+		{Param{Name: "s", Type: ParamString}, " it's ", " it's ", " it's "},
+		{Param{Name: "n", Type: ParamInt}, " -42 ", "-42", int64(-42)},
+		{Param{Name: "d", Type: ParamDecimal}, " -12.30 ", "-12.30", "-123/10"},
+		{Param{Name: "d", Type: ParamDecimal}, "+7", "+7", "7"},
+		{Param{Name: "t", Type: ParamDate}, "20190131", "20190131", "20190131"},
+		{Param{Name: "t", Type: ParamDate}, " 2019-01-31 ", "20190131", "20190131"},
+	}
+	for _, tt := range tests {
+		text, v, err := tt.p.Value(tt.in)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.p.Type, tt.in, err)
+			continue
+		}
+		if d, ok := v.(*hdb.Decimal); ok {
+			v = (*big.Rat)(d).RatString()
+		}
+		if text != tt.text || !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("%s %q = %q, %#v, want %q, %#v", tt.p.Type, tt.in, text, v, tt.text, tt.want)
+		}
+	}
+}
+
+func TestParamValueInvalid(t *testing.T) {
+	tests := []struct {
+		typ string
+		in  string
+	}{
+		{ParamInt, "1.5"},
+		{ParamInt, "0x10"},
+		// big.Rat takes these, a parameter does not
+		{ParamDecimal, "1/3"},
+		{ParamDecimal, "1e3"},
+		{ParamDecimal, "0x10"},
+		{ParamDecimal, "0b11"},
+		{ParamDecimal, "0o17"},
+		{ParamDecimal, "1_000"},
+		{ParamDecimal, ""},
+		{ParamDate, "2019-02-30"},
+		{ParamDate, "31.01.2019"},
+	}
+	for _, tt := range tests {
+		p := Param{Name: "p", Type: tt.typ}
+		if _, v, err := p.Value(tt.in); err == nil {
+			t.Errorf("%s %q = %v, want an error", tt.typ, tt.in, v)
+		}
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	query := `-- report of a company
+-- @param company string
+-- @param start date 20190101
+--   @param Amount  DECIMAL 0.5
+select * from t
+-- @param late int
+where a = {{ company }} and b = {{other}} /* {{commented}} */ and c = '{{quoted}}'
+-- {{line_comment}}
+and d = {{start}} and e = {{other}}`
+
+	params, err := QueryParams(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Param{
+		{Name: "company", Type: ParamString},
+		{Name: "start", Type: ParamDate, Default: "20190101", HasDefault: true},
+		{Name: "Amount", Type: ParamDecimal, Default: "0.5", HasDefault: true},
+		{Name: "other", Type: ParamString},
+		{Name: "quoted", Type: ParamString},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("QueryParams = %+v, want %+v", params, want)
+	}
+}
+
+func TestQueryParamsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"invalid type", "-- @param a float\nselect {{a}}"},
+		{"invalid name", "-- @param 1a string\nselect 1"},
+		{"declared twice", "-- @param a string\n-- @param a int\nselect {{a}}"},
+		{"invalid default", "-- @param a int x\nselect {{a}}"},
+		{"unterminated parameter", "select {{a from t"},
+		{"invalid name in query", "select {{a-b}}"},
+		{"unterminated comment", "select {{a}} /* {{b}}"},
+	}
+	for _, tt := range tests {
+		if _, err := QueryParams(tt.query); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestBindNamed(t *testing.T) {
+	query := `-- @param n int 5
+select '{{s}}', "{{s}}", {{s}}, {{n}} -- {{s}}
+from t /* {{n}} */ where x = 'it''s {{n}}' and y = {{s}}`
+
+	got, args, texts, err := BindNamed(query, map[string]string{"s": "a'b\"c", "other": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `-- @param n int 5
+select 'a''b"c', "a'b""c", ?, ? -- {{s}}
+from t /* {{n}} */ where x = 'it''s 5' and y = ?`
+	if got != want {
+		t.Errorf("query\n%s\nwant\n%s", got, want)
+	}
+	if wantArgs := []interface{}{"a'b\"c", int64(5), "a'b\"c"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args %#v, want %#v", args, wantArgs)
+	}
+	if wantTexts := map[string]string{"s": "a'b\"c", "n": "5"}; !reflect.DeepEqual(texts, wantTexts) {
+		t.Errorf("texts %v, want %v", texts, wantTexts)
+	}
+	if unused := UnusedParams(map[string]string{"s": "", "other": ""}, texts); !reflect.DeepEqual(unused, []string{"other"}) {
+		t.Errorf("UnusedParams = %v, want [other]", unused)
+	}
+
+	if _, _, _, err := BindNamed(query, nil); err == nil {
+		t.Error("no error without a value for s")
+	}
+	if _, _, _, err := BindNamed(query, map[string]string{"s": "a", "n": "x"}); err == nil {
+		t.Error("no error for n=x")
+	}
+	if got, args, texts, err := BindNamed("select 1 -- {{a}}", nil); err != nil || got != "select 1 -- {{a}}" || args != nil || texts != nil {
+		t.Errorf("query without parameters = %q, %v, %v, %v", got, args, texts, err)
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	query := "select '?', \"$$client$$\" -- ? $$coy$$\nfrom t /* ? $$client$$ */ where a = ? and m = $$client$$ and b in ($$coy$$) and c = ?"
+	got, args, err := BindParams(query, []interface{}{1, 2}, "777", []string{"A", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "select '?', \"$$client$$\" -- ? $$coy$$\nfrom t /* ? $$client$$ */ where a = ? and m = ? and b in (?, ?) and c = ?"
+	if got != want {
+		t.Errorf("query\n%s\nwant\n%s", got, want)
+	}
+	if wantArgs := []interface{}{1, "777", "A", "B", 2}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args %#v, want %#v", args, wantArgs)
+	}
+
+	if _, _, err := BindParams("select 1 -- $$coy$$", nil, "777", nil); err != nil {
+		t.Errorf("company codes needed for a comment: %v", err)
+	}
+	if _, _, err := BindParams("select $$coy$$", nil, "777", nil); err == nil {
+		t.Error("no error without company codes")
+	}
+}
+
+func TestReadParams(t *testing.T) {
+	values, err := ReadParams("", []string{"a=1", " b =x=y", "c="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"a": "1", "b": "x=y", "c": ""}; !reflect.DeepEqual(values, want) {
+		t.Errorf("ReadParams = %v, want %v", values, want)
+	}
+	if _, err := ReadParams("", []string{"=1"}); err == nil {
+		t.Error("no error for =1")
+	}
+	if got := ParamList(values); got != "a=1, b=x=y, c=" {
+		t.Errorf("ParamList = %q", got)
+	}
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	hdb "github.com/SAP/go-hdb/driver"
+	"github.com/go-ini/ini"
+)
+
+const (
+	// ProfileEnv - Environment variable naming the profile when --profile is not given
+	ProfileEnv = "HANA_PROFILE"
+
+	// serverSection - Section of the default connection, profiles are [server.<name>]
+	serverSection = "server"
+)
+
+// ServerKeys - Keys every connection needs, a profile inherits the ones it leaves out from [server].
+// uid and pwd can also come from the other sources of loadCredentials.
+var ServerKeys = []string{"host", "port", "uid", "pwd"}
+
+// ProfileName - Profile to use: profile if given, else ProfileEnv, empty for plain [server]
+func ProfileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	return os.Getenv(ProfileEnv)
+}
+
+// Profiles - Names of the [server.<name>] sections of iniCfg, sorted
+func Profiles(iniCfg *ini.File) []string {
+	var names []string
+	for _, section := range iniCfg.ChildSections(serverSection) {
+		names = append(names, strings.TrimPrefix(section.Name(), serverSection+"."))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileSection - Section of profile, [server] if empty
+func profileSection(iniCfg *ini.File, profile string) (*ini.Section, error) {
+	if profile == "" {
+		return iniCfg.Section(serverSection), nil
+	}
+	section, err := iniCfg.GetSection(serverSection + "." + profile)
+	if err != nil {
+		profiles := Profiles(iniCfg)
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q, config has no [server.<name>] sections", profile)
+		}
+		return nil, fmt.Errorf("unknown profile %q, expected one of %s", profile, strings.Join(profiles, ", "))
+	}
+	return section, nil
+}
+
+// checkServer - Problems of the connection settings in section, empty if it is usable
+func checkServer(section *ini.Section) []string {
+	var problems []string
+	var missing []string
+	for _, key := range ServerKeys {
+		switch {
+		case key == "uid" && hasAnyKey(section, userKeys):
+		case key == "pwd" && hasAnyKey(section, passwordKeys):
+		case section.Key(key).String() != "":
+		default:
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) != 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if port := section.Key("port").String(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+			problems = append(problems, fmt.Sprintf("invalid port %q", port))
+		}
+	}
+	for _, key := range []string{"timeout", "query_timeout"} {
+		if v := section.Key(key).String(); v != "" {
+			if n, err := strconv.Atoi(v); err != nil || n < 0 {
+				problems = append(problems, fmt.Sprintf("invalid %s %q, expected seconds", key, v))
+			}
+		}
+	}
+	return problems
+}
+
+// loadServer - Connection settings of section into cfg, config p locates credential files
+func loadServer(cfg *Config, section *ini.Section, p string) error {
+	if problems := checkServer(section); len(problems) != 0 {
+		return fmt.Errorf("[%s]: %s", section.Name(), strings.Join(problems, "; "))
+	}
+	var err error
+	if cfg.User, cfg.password, err = loadCredentials(section, p, cfg.Profile); err != nil {
+		return fmt.Errorf("[%s] %v", section.Name(), err)
+	}
+	cfg.Host = section.Key("host").String()
+	cfg.Port = section.Key("port").String()
+	cfg.Client = section.Key("client").MustString(DefaultClient)
+	cfg.Schema = section.Key("schema").MustString(DefaultSchema)
+	cfg.ConsolSchema = section.Key("consol_schema").MustString(DefaultConsolSchema)
+	cfg.OutputDir = section.Key("output_dir").String()
+	cfg.Timeout = time.Duration(section.Key("timeout").MustInt(0)) * time.Second
+	cfg.QueryTimeout = time.Duration(section.Key("query_timeout").MustInt(0)) * time.Second
+	if cfg.TLS, err = loadTLS(section, p); err != nil {
+		return fmt.Errorf("[%s] %v", section.Name(), err)
+	}
+	return nil
+}
+
+// DSN - hdb connection string of cfg. It holds the password, never print it, see Redact.
+func (cfg Config) DSN() string {
+	return BuildDsn(cfg.Host, cfg.Port, cfg.User, cfg.password, cfg.Timeout)
+}
+
+// Redact - s without the password of cfg, for messages that may contain the DSN
+func (cfg Config) Redact(s string) string {
+	return redact(s, cfg.password)
+}
+
+// Open - Connect to HANA with cfg, encrypted if cfg.TLS is enabled. The password is removed from any error.
+func (cfg Config) Open() (*sql.DB, error) {
+	connector, err := hdb.NewDSNConnector(cfg.DSN())
+	if err != nil {
+		return nil, errors.New(cfg.Redact(err.Error()))
+	}
+	tlsConfig, err := cfg.TLS.Config(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		if err := connector.SetTLSConfig(tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+	db := sql.OpenDB(connector)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.New(cfg.Redact(err.Error()))
+	}
+	return db, nil
+}
+
+// BuildDsn - hdb connection string, user and password are escaped so any character is allowed
+func BuildDsn(host, port, user, password string, timeout time.Duration) string {
+	u := url.URL{
+		Scheme: "hdb",
+		User:   url.UserPassword(user, password),
+		Host:   net.JoinHostPort(host, port),
+	}
+	if timeout > 0 {
+		u.RawQuery = url.Values{hdb.DSNTimeout: {strconv.Itoa(int(timeout / time.Second))}}.Encode()
+	}
+	return u.String()
+}
+
+// ProfileCheck - Result of CheckConfig for one connection
+type ProfileCheck struct {
+	// Name - Profile name, empty for plain [server]
+	Name string
+	// Host - host:port the profile connects to
+	Host string
+	// Problems - Missing or invalid keys, empty if the profile is usable
+	Problems []string
+	// Warnings - Usable but not recommended settings, ie a plain text pwd
+	Warnings []string
+}
+
+// CheckConfig - Validate [server] and every [server.<name>] profile of config p.
+// [server] is only checked on its own when it has a host or there are no profiles,
+// otherwise it just holds the defaults shared by the profiles.
+func CheckConfig(p string) ([]ProfileCheck, error) {
+	if p == "" {
+		p = "config.ini"
+	}
+	iniCfg, err := ini.Load(p)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := Profiles(iniCfg)
+	var names []string
+	if iniCfg.Section(serverSection).HasKey("host") || len(profiles) == 0 {
+		names = append(names, "")
+	}
+	names = append(names, profiles...)
+
+	var checks []ProfileCheck
+	for _, name := range names {
+		section, err := profileSection(iniCfg, name)
+		if err != nil {
+			return nil, err
+		}
+		check := ProfileCheck{Name: name, Problems: checkServer(section)}
+		if len(check.Problems) == 0 {
+			if _, _, err := loadCredentials(section, p, name); err != nil {
+				check.Problems = append(check.Problems, err.Error())
+			}
+		}
+		if len(check.Problems) == 0 {
+			if t, err := loadTLS(section, p); err != nil {
+				check.Problems = append(check.Problems, err.Error())
+			} else if _, err := t.Config(section.Key("host").String()); err != nil {
+				check.Problems = append(check.Problems, err.Error())
+			} else if t.InsecureSkipVerify {
+				check.Warnings = append(check.Warnings, "tls_insecure_skip_verify accepts any server certificate")
+			} else if !t.Enabled {
+				check.Warnings = append(check.Warnings, "connection is not encrypted, set tls = true")
+			}
+		}
+		if sourceKey(section, passwordKeys) == "pwd" {
+			check.Warnings = append(check.Warnings, "pwd is plain text, use pwd_env, pwd_file or secrets_file")
+		}
+		if host := section.Key("host").String(); host != "" {
+			check.Host = net.JoinHostPort(host, section.Key("port").String())
+		}
+		if dir := section.Key("output_dir").String(); dir != "" {
+			if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+				check.Problems = append(check.Problems, fmt.Sprintf("output_dir %q is not a directory", dir))
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// SchemaPlaceholder - Marks the SAP schema in a query, ie from $$schema$$.ekko
+	SchemaPlaceholder = "$$schema$$"
+	// ConsolSchemaPlaceholder - Marks the consolidation schema in a query
+	ConsolSchemaPlaceholder = "$$consol$$"
+	// ClientPlaceholder - Marks the SAP client in a query, ie where mandt = $$client$$.
+	// It is bound as parameter.
+	ClientPlaceholder = "$$client$$"
+
+	// DefaultClient - SAP client used when config has none
+	DefaultClient = "777"
+	// DefaultSchema - SAP schema used when config has none
+	DefaultSchema = "SAPABAP1"
+	// DefaultConsolSchema - Consolidation schema used when config has none
+	DefaultConsolSchema = "Z_WILMAR_CONSODB"
+)
+
+// SchemaName - Identifier of schema s for SQL. Plain names are not case sensitive in HANA,
+// so sapabap1 is written SAPABAP1, anything else is quoted as given.
+func SchemaName(s string) string {
+	if plainIdentifier.MatchString(strings.ToUpper(s)) {
+		return strings.ToUpper(s)
+	}
+	return QuoteIdentifier(s)
+}
+
+// Query - Replace the schema placeholders of query with the configured schemas
+func (cfg Config) Query(query string) string {
+	query = strings.Replace(query, SchemaPlaceholder, SchemaName(cfg.Schema), -1)
+	return strings.Replace(query, ConsolSchemaPlaceholder, SchemaName(cfg.ConsolSchema), -1)
+}
+
+// Prepare - query with schemas filled in, client and bukrs bound as parameters
+func (cfg Config) Prepare(query string, args []interface{}, bukrs []string) (string, []interface{}, error) {
+	return BindParams(cfg.Query(query), args, cfg.Client, bukrs)
+}
+
+// BindParams - Expand ClientPlaceholder into ? and CompanyPlaceholder into one ? per company code,
+// and insert client and bukrs into args at the position of their placeholder
+func BindParams(query string, args []interface{}, client string, bukrs []string) (string, []interface{}, error) {
+	return bindPlaceholders(query, args, map[string][]string{
+		ClientPlaceholder:  {client},
+		CompanyPlaceholder: bukrs,
+	})
+}
+
+// bindPlaceholders - Expand every placeholder of params in query into one ? per value
+// and insert the values into args at that position. ? and placeholders inside quotes or
+// comments are left alone, see scanSQL.
+func bindPlaceholders(query string, args []interface{}, params map[string][]string) (string, []interface{}, error) {
+	var bound []interface{}
+	n := 0
+	query, err := scanSQL(query, func(rest string, quote byte) (string, int, error) {
+		if quote != 0 {
+			return "", 0, nil
+		}
+		if rest[0] == '?' {
+			if n < len(args) {
+				bound = append(bound, args[n])
+			}
+			n++
+			return "", 0, nil
+		}
+		if rest[0] != '$' {
+			return "", 0, nil
+		}
+		for placeholder, values := range params {
+			if !strings.HasPrefix(rest, placeholder) {
+				continue
+			}
+			if len(values) == 0 {
+				if placeholder == CompanyPlaceholder {
+					return "", 0, fmt.Errorf("query needs company codes, use --region or --bukrs")
+				}
+				return "", 0, fmt.Errorf("query needs a value for %s", placeholder)
+			}
+			for _, v := range values {
+				bound = append(bound, v)
+			}
+			return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), len(placeholder), nil
+		}
+		return "", 0, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if n < len(args) {
+		bound = append(bound, args[n:]...)
+	}
+	return query, bound, nil
+}
@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// RedactedPassword - Written instead of the password in DSNs and messages
+	RedactedPassword = "xxxxx"
+
+	// SecretKeySize - Bytes of the AES-256 key of a secrets file
+	SecretKeySize = 32
+)
+
+// userKeys, passwordKeys - Keys of a profile that can supply user and password, see loadCredentials
+var (
+	userKeys     = []string{"uid_env", "uid"}
+	passwordKeys = []string{"pwd_env", "pwd_file", "secrets_file", "pwd"}
+)
+
+// hasAnyKey - True if section has a non empty value for one of keys
+func hasAnyKey(section *ini.Section, keys []string) bool {
+	for _, key := range keys {
+		if section.Key(key).String() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// configPath - file relative to the directory of config p
+func configPath(p, file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(p), file)
+}
+
+// sourceKey - First of keys set in section itself, else the first one inherited from [server].
+// A profile naming its own password source overrides the source of [server].
+func sourceKey(section *ini.Section, keys []string) string {
+	own := make(map[string]bool)
+	for _, key := range section.KeyStrings() {
+		own[key] = true
+	}
+	for _, key := range keys {
+		if own[key] && section.Key(key).String() != "" {
+			return key
+		}
+	}
+	for _, key := range keys {
+		if section.Key(key).String() != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// loadCredentials - User and password of section, config p locates relative files.
+// The password is taken from the first of pwd_env, pwd_file, secrets_file and pwd that is set,
+// the user from uid_env, the secrets file or uid.
+func loadCredentials(section *ini.Section, p, profile string) (string, string, error) {
+	user := section.Key("uid").String()
+	userSource := sourceKey(section, userKeys)
+	if userSource == "uid_env" {
+		name := section.Key("uid_env").String()
+		if user = os.Getenv(name); user == "" {
+			return "", "", fmt.Errorf("uid_env: environment variable %s is not set", name)
+		}
+	}
+
+	switch sourceKey(section, passwordKeys) {
+	case "pwd_env":
+		name := section.Key("pwd_env").String()
+		password := os.Getenv(name)
+		if password == "" {
+			return "", "", fmt.Errorf("pwd_env: environment variable %s is not set", name)
+		}
+		return user, password, nil
+
+	case "pwd_file":
+		file := section.Key("pwd_file").String()
+		b, err := readPrivateFile(configPath(p, file))
+		if err != nil {
+			return "", "", fmt.Errorf("pwd_file: %v", err)
+		}
+		password := strings.TrimRight(string(b), "\r\n")
+		if password == "" {
+			return "", "", fmt.Errorf("pwd_file: %s is empty", file)
+		}
+		return user, password, nil
+
+	case "secrets_file":
+		name := section.Key("secret").String()
+		if name == "" {
+			name = profile
+		}
+		if name == "" {
+			name = serverSection
+		}
+		file := section.Key("secrets_file").String()
+		secretUser, password, err := loadSecret(configPath(p, file), configPath(p, section.Key("key_file").String()), name)
+		if err != nil {
+			return "", "", fmt.Errorf("secrets_file: %v", err)
+		}
+		if secretUser != "" && userSource != "uid_env" {
+			user = secretUser
+		}
+		return user, password, nil
+	}
+
+	return user, section.Key("pwd").String(), nil
+}
+
+// readPrivateFile - Content of p, which must not be readable by group or others
+func readPrivateFile(p string) ([]byte, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	// windows has no unix permission bits, access is left to the ACL
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("%s is accessible by others (mode %04o), restrict it with chmod 600", p, info.Mode().Perm())
+	}
+	return ioutil.ReadFile(p)
+}
+
+// ReadKeyFile - AES key of a secrets file, stored hex encoded in key file p
+func ReadKeyFile(p string) ([]byte, error) {
+	if p == "" {
+		return nil, errors.New("no key_file given")
+	}
+	b, err := readPrivateFile(p)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(key) != SecretKeySize {
+		return nil, fmt.Errorf("%s: expected %d hex encoded bytes", p, SecretKeySize)
+	}
+	return key, nil
+}
+
+// NewSecretKey - Random key for EncryptSecrets, hex encoded as written to a key file
+func NewSecretKey() (string, error) {
+	key := make([]byte, SecretKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// EncryptSecrets - Seal plain with AES-256-GCM, the result is base64 of nonce and ciphertext
+func EncryptSecrets(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(out, sealed)
+	return append(out, '\n'), nil
+}
+
+// DecryptSecrets - Open data written by EncryptSecrets
+func DecryptSecrets(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.New("secrets file is not base64")
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("secrets file is too short")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		// wrong key or changed file, the cipher can not tell which
+		return nil, errors.New("secrets file can not be decrypted with this key")
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadSecret - uid and pwd of section name in the encrypted ini file p, unlocked with keyFile
+func loadSecret(p, keyFile, name string) (string, string, error) {
+	key, err := ReadKeyFile(keyFile)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", "", err
+	}
+	plain, err := DecryptSecrets(key, data)
+	if err != nil {
+		return "", "", err
+	}
+	secrets, err := ini.Load(plain)
+	if err != nil {
+		return "", "", err
+	}
+	section, err := secrets.GetSection(name)
+	if err != nil {
+		return "", "", fmt.Errorf("no [%s] in %s", name, p)
+	}
+	password := section.Key("pwd").String()
+	if password == "" {
+		return "", "", fmt.Errorf("[%s] of %s has no pwd", name, p)
+	}
+	return section.Key("uid").String(), password, nil
+}
+
+// redact - s without password, plain and URL escaped as it appears in a DSN
+func redact(s, password string) string {
+	if password == "" {
+		return s
+	}
+	s = strings.Replace(s, password, RedactedPassword, -1)
+	if escaped := url.UserPassword("", password).String(); escaped != ":"+password {
+		s = strings.Replace(s, escaped[1:], RedactedPassword, -1)
+	}
+	return s
+}
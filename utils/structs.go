@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StructTag - Struct tag naming the column of a field, ie `db:"Group 1"`. Fields without
+// tag use the field name, fields tagged "-" and unexported fields are left out.
+const StructTag = "db"
+
+// structField - Exported field of a struct and its column
+type structField struct {
+	column string
+	index  []int
+}
+
+// structFields - Fields of struct type t in declaration order
+func structFields(t reflect.Type) ([]structField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(StructTag)
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = f.Name
+		}
+		fields = append(fields, structField{column: tag, index: f.Index})
+	}
+	return fields, nil
+}
+
+// elemType - Struct type of v, a struct or a pointer or slice of structs
+func elemType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// StructColumns - Column names of the fields of v, a struct or a pointer or slice of structs
+func StructColumns(v interface{}) ([]string, error) {
+	fields, err := structFields(elemType(v))
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+	return columns, nil
+}
+
+// StructSQL - Query of the columns of v from table
+func StructSQL(table string, v interface{}) (string, error) {
+	columns, err := StructColumns(v)
+	if err != nil {
+		return "", err
+	}
+	for i, c := range columns {
+		columns[i] = QuoteIdentifier(c)
+	}
+	return "select " + strings.Join(columns, ", ") + " from " + table, nil
+}
+
+// ScanStructs - Append every row of rows to the slice dest points to. Result columns are
+// matched to fields by name ignoring case, every column needs a field.
+func ScanStructs(rows *sql.Rows, dest interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("scan into %T, expected a pointer to a slice of structs", dest)
+	}
+	slice = slice.Elem()
+	t := slice.Type().Elem()
+	fields, err := structFields(t)
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	index := make([][]int, len(columns))
+	for i, c := range columns {
+		for _, f := range fields {
+			if strings.EqualFold(f.column, c) {
+				index[i] = f.index
+				break
+			}
+		}
+		if index[i] == nil {
+			return fmt.Errorf("column %s has no field in %s", c, t)
+		}
+	}
+
+	ptrs := make([]interface{}, len(columns))
+	for rows.Next() {
+		v := reflect.New(t).Elem()
+		for i := range index {
+			ptrs[i] = v.FieldByIndex(index[i]).Addr().Interface()
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, v))
+	}
+	return rows.Err()
+}
+
+// StructValues - Field values of struct v as query parameters, NULL as nil
+func StructValues(v interface{}) ([]interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i], err = fieldValue(rv.FieldByIndex(f.index))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// StructRecord - Field values of struct v as text, NULL as empty text
+func StructRecord(v interface{}) ([]string, error) {
+	values, err := StructValues(v)
+	if err != nil {
+		return nil, err
+	}
+	rec := make([]string, len(values))
+	for i, value := range values {
+		rec[i] = fieldText(value)
+	}
+	return rec, nil
+}
+
+// StructFromRecord - Set the fields of the struct dest points to from the values of rec in
+// field order, the reverse of StructRecord. Empty text is NULL in sql.Scanner fields like
+// sql.NullString and the zero value in the others.
+func StructFromRecord(rec []string, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode into %T, expected a pointer to a struct", dest)
+	}
+	rv = rv.Elem()
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	if len(rec) != len(fields) {
+		return fmt.Errorf("%d values, expected %d", len(rec), len(fields))
+	}
+	for i, f := range fields {
+		if err := setField(rv.FieldByIndex(f.index), rec[i]); err != nil {
+			return fmt.Errorf("%s: %v", f.column, err)
+		}
+	}
+	return nil
+}
+
+// setField - Set field f from text s
+func setField(f reflect.Value, s string) error {
+	if scanner, ok := f.Addr().Interface().(sql.Scanner); ok {
+		if s == "" {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan(s)
+	}
+	if s == "" && f.Kind() != reflect.String {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", s)
+		}
+		f.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(strings.TrimSpace(s), f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		f.SetFloat(x)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", s)
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("can not set %s from text", f.Type())
+	}
+	return nil
+}
+
+// fieldValue - Value of a field, a driver.Valuer like sql.NullString gives its Value
+func fieldValue(f reflect.Value) (interface{}, error) {
+	if valuer, ok := f.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return f.Interface(), nil
+}
+
+func fieldText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(v)
+}
+
+// WriteStructsCSV - Write a header of the columns and one record per struct of slice items to w
+func WriteStructsCSV(w *csv.Writer, items interface{}) error {
+	columns, err := StructColumns(items)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	slice := reflect.Indirect(reflect.ValueOf(items))
+	for i := 0; i < slice.Len(); i++ {
+		rec, err := StructRecord(slice.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteStructsJSONL - Write one JSON object per struct of slice items to w, keyed by column
+// in field order, NULL as null
+func WriteStructsJSONL(w io.Writer, items interface{}) error {
+	columns, err := StructColumns(items)
+	if err != nil {
+		return err
+	}
+	slice := reflect.Indirect(reflect.ValueOf(items))
+	for i := 0; i < slice.Len(); i++ {
+		values, err := StructValues(slice.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for j, v := range values {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(columns[j])
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteString("}\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type recordRow struct {
+	Name   string         `db:"NAME"`
+	Note   sql.NullString `db:"Note 1"`
+	Count  int            `db:"COUNT"`
+	Rate   float64
+	Active bool
+	Skip   string `db:"-"`
+	hidden string
+}
+
+func TestStructFromRecord(t *testing.T) {
+	tests := []struct {
+		rec  []string
+		want recordRow
+	}{
+		{
+			rec:  []string{"a", "x", " 3 ", "1.5", "true"},
+			want: recordRow{Name: "a", Note: sql.NullString{String: "x", Valid: true}, Count: 3, Rate: 1.5, Active: true},
+		},
+		{
+			rec:  []string{"", "", "", "", ""},
+			want: recordRow{},
+		},
+	}
+	for _, tt := range tests {
+		got := recordRow{Skip: "kept", hidden: "kept"}
+		if err := StructFromRecord(tt.rec, &got); err != nil {
+			t.Errorf("%q: %v", tt.rec, err)
+			continue
+		}
+		tt.want.Skip, tt.want.hidden = "kept", "kept"
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q = %+v, want %+v", tt.rec, got, tt.want)
+		}
+
+		// StructRecord gives the record back
+		rec, err := StructRecord(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again recordRow
+		if err := StructFromRecord(rec, &again); err != nil {
+			t.Fatal(err)
+		}
+		again.Skip, again.hidden = "kept", "kept"
+		if !reflect.DeepEqual(again, got) {
+			t.Errorf("%q round trips to %+v", tt.rec, again)
+		}
+	}
+}
+
+func TestStructFromRecordInvalid(t *testing.T) {
+	var row recordRow
+	tests := []struct {
+		name string
+		rec  []string
+		dest interface{}
+	}{
+		{"too few values", []string{"a", "x", "1", "1"}, &row},
+		{"too many values", []string{"a", "x", "1", "1", "true", "y"}, &row},
+		{"no integer", []string{"a", "x", "1.5", "1", "true"}, &row},
+		{"no number", []string{"a", "x", "1", "x", "true"}, &row},
+		{"no boolean", []string{"a", "x", "1", "1", "maybe"}, &row},
+		{"no pointer", []string{"a", "x", "1", "1", "true"}, row},
+		{"no struct", []string{"a"}, new(string)},
+	}
+	for _, tt := range tests {
+		if err := StructFromRecord(tt.rec, tt.dest); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
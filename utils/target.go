@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadTarget - Read config p with the connection of profile target for a write.
+// Unlike reads a write never falls back to ProfileEnv or [server], the target must be named.
+func LoadTarget(p, target string) (Config, error) {
+	if target == "" {
+		return Config{}, errors.New("writes need an explicit --target <profile>")
+	}
+	return LoadProfile(p, target)
+}
+
+// ConfirmWrite - Print where a write of action goes and ask to go on, yes skips the question.
+// Anything but y or yes, including no terminal on stdin, aborts.
+func ConfirmWrite(cfg Config, table, action string, yes bool) error {
+	WriteMsg("TARGET: " + cfg.Profile + " (" + cfg.Host + ":" + cfg.Port + ")")
+	WriteMsg("TABLE: " + table)
+	if yes {
+		return nil
+	}
+	fmt.Printf("%s into %s on %s:%s? [y/N] ", action, table, cfg.Host, cfg.Port)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errors.New("aborted, nothing written")
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/go-ini/ini"
+)
+
+// TLS - Encrypted connection settings of a profile, [server] tls_* keys
+type TLS struct {
+	// Enabled - Connect with TLS, [server] tls. Implied by any other tls_* key,
+	// tls = false turns it off for a profile that inherits them.
+	Enabled bool
+	// CAFile - PEM root certificates to verify the server with, system roots if empty
+	CAFile string
+	// ServerName - Name in the server certificate, the host if empty
+	ServerName string
+	// InsecureSkipVerify - Accept any server certificate, for development systems only
+	InsecureSkipVerify bool
+	// CertFile, KeyFile - PEM client certificate and its key, both or none
+	CertFile string
+	KeyFile  string
+}
+
+// loadTLS - TLS settings of section, config p locates relative files
+func loadTLS(section *ini.Section, p string) (TLS, error) {
+	t := TLS{
+		CAFile:     configPath(p, section.Key("tls_ca_file").String()),
+		ServerName: section.Key("tls_server_name").String(),
+		CertFile:   configPath(p, section.Key("tls_cert_file").String()),
+		KeyFile:    configPath(p, section.Key("tls_key_file").String()),
+	}
+	var err error
+	if t.InsecureSkipVerify, err = keyBool(section, "tls_insecure_skip_verify"); err != nil {
+		return t, err
+	}
+	implied := t.CAFile != "" || t.ServerName != "" || t.InsecureSkipVerify || t.CertFile != ""
+	if t.Enabled, err = keyBool(section, "tls"); err != nil {
+		return t, err
+	}
+	if section.Key("tls").String() == "" {
+		t.Enabled = implied
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return t, errors.New("tls_cert_file and tls_key_file must be given together")
+	}
+	return t, nil
+}
+
+// keyBool - Boolean key of section, false if empty
+func keyBool(section *ini.Section, key string) (bool, error) {
+	v := section.Key(key).String()
+	if v == "" {
+		return false, nil
+	}
+	b, err := section.Key(key).Bool()
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q, expected true or false", key, v)
+	}
+	return b, nil
+}
+
+// Config - crypto/tls configuration for host, nil if TLS is not enabled
+func (t TLS) Config(host string) (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+	c := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if c.ServerName == "" {
+		c.ServerName = host
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls_ca_file: %v", err)
+		}
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls_ca_file: no PEM certificate in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" {
+		if _, err := readPrivateFile(t.KeyFile); err != nil {
+			return nil, fmt.Errorf("tls_key_file: %v", err)
+		}
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls_cert_file: %v", err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"encoding/csv"
 	"fmt"
@@ -10,22 +11,13 @@ import (
 	"os"
 	"time"
 
+	hdb "github.com/SAP/go-hdb/driver"
 	"github.com/go-ini/ini"
 )
 
 const (
 	// DriverName - Default driver name for HANA DB from SAP
 	DriverName = "hdb"
-
-	// IndoCoy - Default coy for Indo
-	IndoCoy = `'BM', 'BO', 'CL', 'DE', 'EB', 'EC', 'EE', 'EL', 'EP', 'ES', 'FB', 'FM', 'GM', 'GU', 'HM', 'JW', 'KI', 'KM', 'NE', 'NO', 'NS', 'NX', 'OE', 'PB', 'PE', 'PO', 'RB', 'RH', 'RM', 'SE', 'SF', 'SG', 'SH', 'SO', 'SU', 'VI', 'WH',
-	'AA', 'AD', 'AG', 'AJ', 'AN', 'AP', 'BN', 'BV', 'BW', 'BX', 'BY', 'CA', 'CC', 'CX', 'DA',
-	'DB', 'DC', 'DG', 'DI', 'GA', 'GK', 'IA', 'ID', 'IE', 'IF', 'KD', 'KF', 'KG', 'MD', 'MF', 'MH',
-	'MJ', 'MO', 'NI', 'PA', 'PF', 'PR', 'PT', 'PV', 'PX', 'RA', 'RJ',
-	'SB', 'SJ', 'SN', 'SV', 'SX', 'TB', 'TC', 'TM', 'TN', 'UD', 'UI', 'WJ'`
-
-	// AfricaCoy - Default coy for Africa
-	AfricaCoy = `'BC', 'EY', 'BZ', 'OU'`
 )
 
 // NullTime - Nullable time.Time
@@ -48,28 +40,123 @@ func (nt NullTime) Value() (driver.Value, error) {
 	return nt.Time, nil
 }
 
-// ReadConfig - Read config from ini files
-func ReadConfig(p string) (string, string, error) {
+// Lob - Scan target for CLOB, NCLOB and BLOB keeping the content in memory
+type Lob struct {
+	bytes.Buffer
+	Valid bool
+}
+
+// Scan implements the Scanner interface.
+func (l *Lob) Scan(value interface{}) error {
+	l.Reset()
+	l.Valid = value != nil
+	if !l.Valid {
+		return nil
+	}
+	return hdb.NewLob(nil, &l.Buffer).Scan(value)
+}
+
+// Config - Settings read from config.ini
+type Config struct {
+	// Profile - Selected connection profile, empty for plain [server]
+	Profile string
+	// Host, Port, User - Connection of the profile, see DSN
+	Host string
+	Port string
+	User string
+	// password - Resolved password, unexported so it is never printed with the config
+	password string
+	// Client - SAP client (MANDT), [server] client
+	Client string
+	// Schema - SAP schema, [server] schema
+	Schema string
+	// ConsolSchema - Consolidation schema, [server] consol_schema
+	ConsolSchema string
+	// OutputDir - Directory of output files, [server] output_dir, current directory if empty
+	OutputDir string
+	// Timeout - Connect timeout, [server] timeout in seconds, 0 for the driver default
+	Timeout time.Duration
+	// QueryTimeout - Limit of one query, [server] query_timeout in seconds, 0 for none
+	QueryTimeout time.Duration
+	// TLS - Encrypted connection, [server] tls_* keys
+	TLS TLS
+	// Extension - Output file extension, [save] extension
+	Extension string
+	// Scale - Digits after decimal point for DECIMAL, 0 keeps the exact value
+	Scale int
+	// Null - Text written for NULL values, [save] null
+	Null string
+	// Format - Output format, [save] format: csv, tsv, jsonl or parquet
+	Format string
+	// Delimiter - CSV field delimiter, [save] delimiter
+	Delimiter string
+	// Quote - CSV quoting, [save] quote: minimal, all or none
+	Quote string
+	// CRLF - End CSV lines with \r\n, [save] crlf
+	CRLF bool
+	// Stitch - Join the part files of a chunked extract into one file, [save] stitch
+	Stitch bool
+	// DeltaState - High-water marks of delta extracts, [save] delta_state
+	DeltaState string
+	// BatchSize - Rows per bulk insert round trip of load, [load] batch_size
+	BatchSize int
+	// SnapshotDir - Archive of table snapshots taken before uploads, [load] snapshot_dir
+	SnapshotDir string
+	// ResultsDir - Store of the perftest runs compared against baselines, [perftest] results_dir
+	ResultsDir string
+	// Region - Default company group, [region] default
+	Region string
+	// Regions - Company codes per group name, [region]
+	Regions map[string][]string
+}
+
+// LoadConfig - Read config from ini files into Config, profile from ProfileEnv
+func LoadConfig(p string) (Config, error) {
+	return LoadProfile(p, "")
+}
+
+// LoadProfile - Read config from ini files into Config with the connection of profile,
+// see ProfileName. A profile [server.<name>] inherits every key it leaves out from [server].
+func LoadProfile(p, profile string) (Config, error) {
+	var cfg Config
 	if p == "" {
 		p = "config.ini"
 	}
 	iniCfg, err := ini.Load(p)
 	if err != nil {
 		WriteMsg("CONFIG")
-		// log.Fatal(err)
-		return "", "", err
+		return cfg, err
+	}
+	cfg.Profile = ProfileName(profile)
+	iniSection, err := profileSection(iniCfg, cfg.Profile)
+	if err != nil {
+		return cfg, err
+	}
+	if err := loadServer(&cfg, iniSection, p); err != nil {
+		return cfg, fmt.Errorf("%s %v", p, err)
 	}
-	iniSection := iniCfg.Section("server")
-	iniKeyUsername := iniSection.Key("uid").String()
-	iniKeyPassword := iniSection.Key("pwd").String()
-	iniKeyHost := iniSection.Key("host").String()
-	iniKeyPort := iniSection.Key("port").String()
-	hdbDsn := "hdb://" + iniKeyUsername + ":" + iniKeyPassword + "@" + iniKeyHost + ":" + iniKeyPort
 
 	iniSaveSection := iniCfg.Section("save")
-	iniExtension := iniSaveSection.Key("extension").String()
+	cfg.Extension = iniSaveSection.Key("extension").String()
+	cfg.Scale = iniSaveSection.Key("scale").MustInt(0)
+	cfg.Null = iniSaveSection.Key("null").String()
+	cfg.Format = iniSaveSection.Key("format").String()
+	cfg.Delimiter = iniSaveSection.Key("delimiter").String()
+	cfg.Quote = iniSaveSection.Key("quote").String()
+	cfg.CRLF = iniSaveSection.Key("crlf").MustBool(false)
+	cfg.Stitch = iniSaveSection.Key("stitch").MustBool(true)
+	cfg.DeltaState = iniSaveSection.Key("delta_state").String()
+
+	cfg.BatchSize = iniCfg.Section("load").Key("batch_size").MustInt(0)
+	cfg.SnapshotDir = iniCfg.Section("load").Key("snapshot_dir").String()
+
+	cfg.ResultsDir = iniCfg.Section("perftest").Key("results_dir").String()
 
-	return hdbDsn, iniExtension, nil
+	if err := loadRegions(&cfg, iniCfg, p); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
 }
 
 // WriteMsg - Just a wrapper of fmt.Print()
@@ -77,6 +164,25 @@ func WriteMsg(s string) {
 	fmt.Println(s)
 }
 
+// WriteFileAtomic - Write p with write through the temporary file p.tmp, renamed to p once
+// write is done, so a crash or an error never leaves half a file behind at p
+func WriteFileAtomic(p string, write func(w io.Writer) error) error {
+	file, err := os.Create(p + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(p + ".tmp")
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(p + ".tmp")
+		return err
+	}
+	return os.Rename(p+".tmp", p)
+}
+
 // DecodeDecimal - Copy code from SAP drive to enable decode Decimals
 func DecodeDecimal(b []byte, m *big.Int) (bool, int) {
 
@@ -125,6 +231,8 @@ func DecodeDecimal(b []byte, m *big.Int) (bool, int) {
 }
 
 // BigIntToFloat - Convert to float
+//
+// Deprecated: float64 loses precision on large amounts, use ParseDecimal.
 func BigIntToFloat(sign bool, m *big.Int, exp int) float64 {
 	var neg int64
 	if sign {
@@ -166,15 +274,11 @@ func ReadCsv(f string, comma rune) (rec [][]string, count int) {
 	return WholeRecord, lineCount
 }
 
-// ConvertByteToStr = ConvertByteToStr
+// ConvertByteToStr - Render HANA DECIMAL bytes with 4 digits after decimal point
 func ConvertByteToStr(b []byte, bi big.Int) string {
-	//var bi big.Int
-	var z float64
-	var neg bool
-	var i int
-
-	neg, i = DecodeDecimal(b, &bi)
-	z = BigIntToFloat(neg, &bi, i)
-	//record = append(record, fmt.Sprintf("%.4f", z))
-	return fmt.Sprintf("%.4f", z)
+	d, err := ParseDecimal(b)
+	if err != nil {
+		return ""
+	}
+	return d.StringFixed(4)
 }